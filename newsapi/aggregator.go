@@ -0,0 +1,203 @@
+package newsapi
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultAggregatorConcurrency = 4
+)
+
+// Query is a named query run by an Aggregator, made by one of the query functions below
+type Query struct {
+	Name  string
+	fetch func(api NewsApi) ([]*News, error)
+}
+
+// NewTopQuery returns a query for the top news
+func NewTopQuery(name string) Query {
+	return Query{Name: name, fetch: NewsApi.GetTopNews}
+}
+
+// NewTopicQuery returns a query for the news of a topic
+func NewTopicQuery(name, topic string) Query {
+	return Query{Name: name, fetch: func(api NewsApi) ([]*News, error) {
+		return api.GetTopicNews(topic)
+	}}
+}
+
+// NewSearchQuery returns a query searching the news
+func NewSearchQuery(name, query string) Query {
+	return Query{Name: name, fetch: func(api NewsApi) ([]*News, error) {
+		return api.SearchNews(query)
+	}}
+}
+
+// NewLocationQuery returns a query for the news of a location
+func NewLocationQuery(name, location string) Query {
+	return Query{Name: name, fetch: func(api NewsApi) ([]*News, error) {
+		return api.GetLocationNews(location)
+	}}
+}
+
+// NewFeedQuery returns a query for the news of a feed url
+func NewFeedQuery(name, feedURL string) Query {
+	return Query{Name: name, fetch: func(api NewsApi) ([]*News, error) {
+		return api.GetFeedNews(feedURL)
+	}}
+}
+
+// validate checks that the query has a name and was made by one of the query functions
+func (q Query) validate() error {
+	if q.Name == "" {
+		return ErrEmptyQueryName
+	}
+	if q.fetch == nil {
+		return fmt.Errorf("%w: %s", ErrInvalidQuery, q.Name)
+	}
+	return nil
+}
+
+// QueryError is the error of a single query in an aggregator
+type QueryError struct {
+	Query string
+	Err   error
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("query %s: %s", e.Query, e.Err)
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// QueryErrors collects the errors of the failed queries in an aggregator
+type QueryErrors []*QueryError
+
+func (e QueryErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d query(s) failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Aggregator runs several named queries and merges their news into one timeline
+type Aggregator struct {
+	api                NewsApi
+	queries            []Query
+	concurrency        int
+	resolveSourceLinks bool
+	limit              int
+}
+
+type AggregatorOption func(*Aggregator)
+
+// WithAggregatorConcurrency sets how many queries are run at the same time
+func WithAggregatorConcurrency(concurrency int) AggregatorOption {
+	return func(a *Aggregator) {
+		if concurrency > 0 {
+			a.concurrency = concurrency
+		}
+	}
+}
+
+// WithAggregatorLimit sets the maximal number of news in the timeline, 0 means no limit
+func WithAggregatorLimit(limit int) AggregatorOption {
+	return func(a *Aggregator) {
+		a.limit = limit
+	}
+}
+
+// WithoutAggregatorSourceLinks de-duplicates without resolving the source links of google news links
+func WithoutAggregatorSourceLinks() AggregatorOption {
+	return func(a *Aggregator) {
+		a.resolveSourceLinks = false
+	}
+}
+
+// NewAggregator returns an aggregator running the queries with api
+func NewAggregator(api NewsApi, options ...AggregatorOption) *Aggregator {
+	a := &Aggregator{
+		api:                api,
+		concurrency:        defaultAggregatorConcurrency,
+		resolveSourceLinks: true,
+	}
+	for _, option := range options {
+		option(a)
+	}
+	return a
+}
+
+// AddQuery adds queries to the aggregator, names must be unique
+func (a *Aggregator) AddQuery(queries ...Query) error {
+	for _, q := range queries {
+		if err := q.validate(); err != nil {
+			return err
+		}
+		for _, existing := range a.queries {
+			if existing.Name == q.Name {
+				return fmt.Errorf("%w: %s", ErrDuplicateQueryName, q.Name)
+			}
+		}
+		a.queries = append(a.queries, q)
+	}
+	return nil
+}
+
+// Run runs every query concurrently and returns the merged news, newest first.
+// Each news records the names of the queries it matched in Queries.
+// When only some queries fail, the timeline is returned along with a QueryErrors.
+func (a *Aggregator) Run() ([]*News, error) {
+	if len(a.queries) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	results := make([][]*News, len(a.queries))
+	errs := make([]error, len(a.queries))
+	sem := make(chan struct{}, a.concurrency)
+	var wg sync.WaitGroup
+	for i, q := range a.queries {
+		wg.Add(1)
+		go func(i int, q Query) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i], errs[i] = q.fetch(a.api)
+		}(i, q)
+	}
+	wg.Wait()
+
+	var queryErrs QueryErrors
+	var newsList []*News
+	for i, q := range a.queries {
+		if errs[i] != nil {
+			queryErrs = append(queryErrs, &QueryError{Query: q.Name, Err: errs[i]})
+			continue
+		}
+		for _, news := range results[i] {
+			news.Queries = appendStrings(news.Queries, q.Name)
+		}
+		newsList = append(newsList, results[i]...)
+	}
+	if len(queryErrs) == len(a.queries) {
+		return nil, queryErrs
+	}
+
+	if a.resolveSourceLinks {
+		a.api.FetchSourceLinks(newsList, WithFetchConcurrency(a.concurrency))
+	}
+	newsList = MergeNews(newsList)
+	SortNews(newsList, SortByPublishedDesc)
+	if a.limit > 0 && a.limit < len(newsList) {
+		newsList = newsList[:a.limit]
+	}
+
+	if len(queryErrs) > 0 {
+		return newsList, queryErrs
+	}
+	return newsList, nil
+}
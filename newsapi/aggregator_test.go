@@ -0,0 +1,89 @@
+package newsapi
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Zhima-Mochi/newsApi-go/newsapi/newsapitest"
+)
+
+func TestAggregator(t *testing.T) {
+	server := newsapitest.NewServer()
+	defer server.Close()
+	articles := testArticles(server)
+	server.SetTopNews(newsapitest.Fixture{Articles: articles})
+	server.SetTopicNews("BUSINESS", newsapitest.Fixture{Articles: articles[1:]})
+	server.SetSearchNews("chips", newsapitest.Fixture{Articles: articles[:1]})
+	server.SetPage("/feeds/missing.xml", newsapitest.Fixture{Status: http.StatusNotFound})
+
+	// the transport sends the source link requests to the server too
+	a := NewAggregator(NewNewsApi(WithTransport(server.Transport())))
+	if err := a.AddQuery(
+		NewSearchQuery("chips", "chips"),
+		NewTopicQuery("business", TopicBusiness),
+		NewTopQuery("top"),
+		NewFeedQuery("missing", server.URL+"/feeds/missing.xml"),
+	); err != nil {
+		t.Fatal(err)
+	}
+	newsList, err := a.Run()
+	var queryErrs QueryErrors
+	if !errors.As(err, &queryErrs) || len(queryErrs) != 1 || queryErrs[0].Query != "missing" {
+		t.Fatalf("got error %v, want the missing feed error only", err)
+	}
+
+	// the news of every query are merged by source link, newest first
+	if len(newsList) != 2 {
+		t.Fatalf("got %d news, want 2", len(newsList))
+	}
+	wantQueries := [][]string{{"chips", "top"}, {"business", "top"}}
+	for i, news := range newsList {
+		if news.SourceLink != articles[i].SourceURL {
+			t.Errorf("news %d: got source link %q, want %q", i, news.SourceLink, articles[i].SourceURL)
+		}
+		if len(news.Queries) != 2 || news.Queries[0] != wantQueries[i][0] || news.Queries[1] != wantQueries[i][1] {
+			t.Errorf("news %d: got queries %v, want %v", i, news.Queries, wantQueries[i])
+		}
+	}
+
+	a = NewAggregator(NewNewsApi(WithTransport(server.Transport())), WithAggregatorLimit(1), WithoutAggregatorSourceLinks())
+	a.AddQuery(NewTopQuery("top"))
+	if newsList, err := a.Run(); err != nil || len(newsList) != 1 || newsList[0].SourceLink != "" {
+		t.Errorf("limited timeline: got %v, %v", newsList, err)
+	}
+}
+
+func TestAggregatorErrors(t *testing.T) {
+	failure := errors.New("unavailable")
+	a := NewAggregator(nil)
+	if _, err := a.Run(); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("no query: got %v, want ErrEmptyQuery", err)
+	}
+	for _, tt := range []struct {
+		query Query
+		want  error
+	}{
+		{Query{fetch: NewsApi.GetTopNews}, ErrEmptyQueryName},
+		{Query{Name: "zero"}, ErrInvalidQuery},
+	} {
+		if err := a.AddQuery(tt.query); !errors.Is(err, tt.want) {
+			t.Errorf("got %v, want %v", err, tt.want)
+		}
+	}
+
+	failing := func(name string) Query {
+		return Query{Name: name, fetch: func(api NewsApi) ([]*News, error) { return nil, failure }}
+	}
+	if err := a.AddQuery(failing("a"), failing("b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AddQuery(failing("a")); !errors.Is(err, ErrDuplicateQueryName) {
+		t.Errorf("duplicate name: got %v, want ErrDuplicateQueryName", err)
+	}
+	newsList, err := a.Run()
+	var queryErrs QueryErrors
+	if newsList != nil || !errors.As(err, &queryErrs) || len(queryErrs) != 2 || !errors.Is(queryErrs[1], failure) {
+		t.Errorf("every query failed: got %v, %v", newsList, err)
+	}
+}
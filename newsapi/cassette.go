@@ -0,0 +1,317 @@
+package newsapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gocolly/colly"
+)
+
+// RecorderMode is the mode of a Recorder
+type RecorderMode int
+
+const (
+	// ModeReplay replays the cassette and fails the requests it does not contain
+	ModeReplay RecorderMode = iota
+	// ModeRecord records every request to a new cassette
+	ModeRecord
+	// ModeRecordMissing replays the cassette and records the requests it does not contain
+	ModeRecordMissing
+)
+
+// Cassette is the recorded interactions of a Recorder, saved as json
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response
+type Interaction struct {
+	Request    CassetteRequest  `json:"request"`
+	Response   CassetteResponse `json:"response"`
+	RecordedAt time.Time        `json:"recorded_at"`
+}
+
+// CassetteRequest is a recorded request
+type CassetteRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Header  http.Header `json:"header,omitempty"`
+	Body    string      `json:"body,omitempty"`
+	Base64  bool        `json:"base64,omitempty"`
+	matched bool
+}
+
+// CassetteResponse is a recorded response
+type CassetteResponse struct {
+	StatusCode int         `json:"status_code"`
+	Status     string      `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	// Base64 tells whether the body is base64 encoded, for bodies which are not utf-8
+	Base64 bool `json:"base64,omitempty"`
+}
+
+// Recorder is a transport recording http interactions to a cassette file and replaying them deterministically
+type Recorder struct {
+	mu        sync.Mutex
+	path      string
+	mode      RecorderMode
+	transport http.RoundTripper
+	matcher   func(req *http.Request, recorded *CassetteRequest) bool
+	redacted  []string
+	cassette  *Cassette
+	modified  bool
+}
+
+type RecorderOption func(*Recorder)
+
+// WithRecorderTransport sets the transport of the recorded requests.
+// By default it is the transport of the api client with WithRecorder, and http.DefaultTransport otherwise.
+func WithRecorderTransport(transport http.RoundTripper) RecorderOption {
+	return func(r *Recorder) {
+		r.transport = transport
+	}
+}
+
+// WithRecorderMatcher sets how a request matches a recorded request, same method and url by default
+func WithRecorderMatcher(matcher func(req *http.Request, recorded *CassetteRequest) bool) RecorderOption {
+	return func(r *Recorder) {
+		r.matcher = matcher
+	}
+}
+
+// WithRedactedHeaders sets the headers which are not recorded, Authorization, Cookie and Set-Cookie by default
+func WithRedactedHeaders(headers ...string) RecorderOption {
+	return func(r *Recorder) {
+		r.redacted = headers
+	}
+}
+
+// NewRecorder returns a recorder of the cassette at path.
+// The cassette must exist in ModeReplay, and is saved by Save in the record modes.
+func NewRecorder(path string, mode RecorderMode, options ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{
+		path:     path,
+		mode:     mode,
+		matcher:  matchMethodAndURL,
+		redacted: []string{"Authorization", "Cookie", "Set-Cookie"},
+		cassette: &Cassette{},
+	}
+	for _, option := range options {
+		option(r)
+	}
+	if mode == ModeRecord {
+		return r, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && mode == ModeRecordMissing {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading cassette: %w", err)
+	}
+	if err := json.Unmarshal(data, r.cassette); err != nil {
+		return nil, fmt.Errorf("error decoding cassette: %w", err)
+	}
+	return r, nil
+}
+
+// matchMethodAndURL matches requests with the same method and url, ignoring the order of the query parameters
+func matchMethodAndURL(req *http.Request, recorded *CassetteRequest) bool {
+	if req.Method != recorded.Method {
+		return false
+	}
+	u, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == req.URL.Scheme && u.Host == req.URL.Host && u.Path == req.URL.Path &&
+		u.Query().Encode() == req.URL.Query().Encode()
+}
+
+// AttachCollector makes the collector use the recorder, see also WithFetchTransport
+func (r *Recorder) AttachCollector(c *colly.Collector) {
+	c.WithTransport(r)
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r.roundTrip(req, nil)
+}
+
+// roundTrip replays or records the request, recording with transport unless the recorder has its own
+func (r *Recorder) roundTrip(req *http.Request, transport http.RoundTripper) (*http.Response, error) {
+	if r.mode != ModeRecord {
+		resp, ok, err := r.replay(req)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return resp, nil
+		}
+		if r.mode == ModeReplay {
+			return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL)
+		}
+	}
+	if r.transport != nil {
+		transport = r.transport
+	}
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return r.record(req, transport)
+}
+
+// recorderTransport records with the transport of a client, see newsApi.HTTPClient
+type recorderTransport struct {
+	recorder  *Recorder
+	transport http.RoundTripper
+}
+
+func (t *recorderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.recorder.roundTrip(req, t.transport)
+}
+
+// replay returns the response of the first unused matching interaction, or of the last matching one once all are used
+func (r *Recorder) replay(req *http.Request) (*http.Response, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found *Interaction
+	for _, interaction := range r.cassette.Interactions {
+		if !r.matcher(req, &interaction.Request) {
+			continue
+		}
+		found = interaction
+		if !interaction.Request.matched {
+			break
+		}
+	}
+	if found == nil {
+		return nil, false, nil
+	}
+	found.Request.matched = true
+
+	body, err := decodeCassetteBody(found.Response.Body, found.Response.Base64)
+	if err != nil {
+		return nil, false, fmt.Errorf("error decoding recorded response of %s %s: %w", req.Method, req.URL, err)
+	}
+	return &http.Response{
+		Status:        found.Response.Status,
+		StatusCode:    found.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        found.Response.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, true, nil
+}
+
+func (r *Recorder) record(req *http.Request, transport http.RoundTripper) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := &Interaction{
+		Request: CassetteRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: r.redact(req.Header),
+		},
+		Response: CassetteResponse{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Header:     r.redact(resp.Header),
+		},
+		RecordedAt: time.Now().UTC(),
+	}
+	interaction.Request.Body, interaction.Request.Base64 = encodeCassetteBody(reqBody)
+	interaction.Response.Body, interaction.Response.Base64 = encodeCassetteBody(respBody)
+	interaction.Request.matched = true
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.modified = true
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func (r *Recorder) redact(header http.Header) http.Header {
+	header = header.Clone()
+	for _, key := range r.redacted {
+		header.Del(key)
+	}
+	return header
+}
+
+// Interactions returns the interactions of the cassette
+func (r *Recorder) Interactions() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Interaction(nil), r.cassette.Interactions...)
+}
+
+// Save writes the cassette if new interactions were recorded
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.modified {
+		return nil
+	}
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r.cassette); err != nil {
+		return fmt.Errorf("error encoding cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("error creating cassette directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, data.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("error writing cassette: %w", err)
+	}
+	r.modified = false
+	return nil
+}
+
+// encodeCassetteBody returns the body as a string, base64 encoded if it is not utf-8
+func encodeCassetteBody(body []byte) (string, bool) {
+	if utf8.Valid(body) {
+		return string(body), false
+	}
+	return base64.StdEncoding.EncodeToString(body), true
+}
+
+// decodeCassetteBody returns the body of encodeCassetteBody
+func decodeCassetteBody(body string, encoded bool) ([]byte, error) {
+	if !encoded {
+		return []byte(body), nil
+	}
+	return base64.StdEncoding.DecodeString(body)
+}
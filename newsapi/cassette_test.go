@@ -0,0 +1,74 @@
+package newsapi
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Zhima-Mochi/newsApi-go/newsapi/newsapitest"
+)
+
+func TestRecorder(t *testing.T) {
+	server := newsapitest.NewServer()
+	server.SetTopNews(newsapitest.Fixture{Articles: []newsapitest.Article{
+		{Title: "Chip exports rise", Publisher: "Reuters", PublisherURL: "https://www.reuters.com", SourceURL: server.URL + "/articles/chips", Published: time.Now().Add(-time.Hour)},
+	}})
+	path := filepath.Join(t.TempDir(), "cassettes", "top.json")
+
+	// the recorder records with the transport of the client, which sends news.google.com to the server
+	recorder, err := NewRecorder(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := NewNewsApi(WithTransport(server.Transport()), WithRecorder(recorder))
+	recorded, err := api.GetTopNews()
+	if err != nil {
+		t.Fatalf("recording: %v", err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+	if interactions := recorder.Interactions(); len(interactions) != 1 || interactions[0].Request.Header.Get("User-Agent") == "" {
+		t.Fatalf("got interactions %+v", interactions)
+	}
+
+	recorder, err = NewRecorder(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api = NewNewsApi(WithRecorder(recorder))
+	replayed, err := api.GetTopNews()
+	if err != nil {
+		t.Fatalf("replaying: %v", err)
+	}
+	if len(replayed) != len(recorded) || replayed[0].Title != recorded[0].Title {
+		t.Errorf("replayed %d news, recorded %d", len(replayed), len(recorded))
+	}
+	if _, err := api.SearchNews("not recorded"); !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("unrecorded request: got %v, want ErrNoInteraction", err)
+	}
+}
+
+func TestRecorderInvalidBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	cassette := `{"interactions": [{"request": {"method": "GET", "url": "https://example.com/feed"},
+"response": {"status_code": 200, "status": "200 OK", "body": "not base64!", "base64": true}}]}`
+	if err := os.WriteFile(path, []byte(cassette), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	recorder, err := NewRecorder(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: recorder}
+	if _, err := client.Get("https://example.com/feed"); err == nil {
+		t.Error("invalid base64 body: got no error")
+	}
+	if _, err := NewNewsApi(WithRecorder(recorder)).GetFeedNews("https://example.com/feed"); err == nil {
+		t.Error("feed of an invalid base64 body: got no error")
+	}
+}
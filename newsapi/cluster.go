@@ -0,0 +1,275 @@
+package newsapi
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultClusterThreshold = 0.3
+	defaultLabelCount       = 3
+	// titleWeight is how many times the title terms count compared to the description and content
+	titleWeight = 2
+)
+
+var (
+	// stopWords are the english words ignored by the clustering
+	stopWords = map[string]struct{}{
+		"a": {}, "about": {}, "after": {}, "all": {}, "also": {}, "an": {}, "and": {}, "are": {}, "as": {}, "at": {},
+		"be": {}, "been": {}, "but": {}, "by": {}, "can": {}, "could": {}, "for": {}, "from": {}, "had": {}, "has": {},
+		"have": {}, "he": {}, "her": {}, "his": {}, "how": {}, "i": {}, "if": {}, "in": {}, "into": {}, "is": {},
+		"it": {}, "its": {}, "more": {}, "new": {}, "not": {}, "of": {}, "on": {}, "or": {}, "our": {}, "over": {},
+		"says": {}, "she": {}, "so": {}, "than": {}, "that": {}, "the": {}, "their": {}, "they": {}, "this": {}, "to": {},
+		"up": {}, "was": {}, "we": {}, "were": {}, "what": {}, "when": {}, "which": {}, "who": {}, "will": {}, "with": {},
+		"would": {}, "you": {},
+	}
+)
+
+// Story is a cluster of news about the same story
+type Story struct {
+	// Labels are the top terms of the story
+	Labels []string
+	// News are the news of the story in the order they were added
+	News []*News
+	// FirstSeen is the earliest published date of the news, or when the first news was added
+	FirstSeen time.Time
+	// LastSeen is the latest published date of the news, or when the last news was added
+	LastSeen time.Time
+	// Publishers counts the news of every publisher
+	Publishers map[string]int
+
+	// termCounts sums the term frequencies of the news
+	termCounts map[string]float64
+}
+
+// Size returns the number of news of the story
+func (s *Story) Size() int {
+	return len(s.News)
+}
+
+// PublisherCount returns the number of distinct publishers of the story
+func (s *Story) PublisherCount() int {
+	return len(s.Publishers)
+}
+
+// Clusterer groups news into stories with single-pass tf-idf clustering
+type Clusterer struct {
+	mu sync.Mutex
+
+	threshold  float64
+	labelCount int
+
+	docs     int
+	docFreqs map[string]int
+	// surfaces maps the stems to the first word seen, used for the labels
+	surfaces map[string]string
+	stories  []*Story
+}
+
+type ClustererOption func(*Clusterer)
+
+// WithClusterThreshold sets the minimal cosine similarity of a news to join a story
+func WithClusterThreshold(threshold float64) ClustererOption {
+	return func(c *Clusterer) {
+		c.threshold = threshold
+	}
+}
+
+// WithLabelCount sets the number of top terms labelling a story
+func WithLabelCount(count int) ClustererOption {
+	return func(c *Clusterer) {
+		if count > 0 {
+			c.labelCount = count
+		}
+	}
+}
+
+// NewClusterer returns a clusterer which groups news incrementally with Add
+func NewClusterer(options ...ClustererOption) *Clusterer {
+	c := &Clusterer{
+		threshold:  defaultClusterThreshold,
+		labelCount: defaultLabelCount,
+		docFreqs:   map[string]int{},
+		surfaces:   map[string]string{},
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// ClusterNews groups the news into stories in one batch, largest stories first
+func ClusterNews(newsList []*News, options ...ClustererOption) []*Story {
+	c := NewClusterer(options...)
+	for _, news := range newsList {
+		c.Add(news)
+	}
+	return c.Stories()
+}
+
+// Add adds the news to the most similar story, or to a new story if none is similar enough
+func (c *Clusterer) Add(news *News) *Story {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	terms := newsTerms(news, c.surfaces)
+	c.docs++
+	for term := range terms {
+		c.docFreqs[term]++
+	}
+
+	var best *Story
+	bestSimilarity := 0.0
+	for _, story := range c.stories {
+		similarity := c.similarity(terms, story)
+		if similarity > bestSimilarity {
+			best, bestSimilarity = story, similarity
+		}
+	}
+	if best == nil || bestSimilarity < c.threshold {
+		best = &Story{
+			Publishers: map[string]int{},
+			termCounts: map[string]float64{},
+		}
+		c.stories = append(c.stories, best)
+	}
+
+	best.News = append(best.News, news)
+	for term, count := range terms {
+		best.termCounts[term] += count
+	}
+	if publisher := news.Publisher(); publisher != "" {
+		best.Publishers[publisher]++
+	}
+	seen := time.Now()
+	if news.PublishedParsed != nil {
+		seen = *news.PublishedParsed
+	}
+	if best.FirstSeen.IsZero() || seen.Before(best.FirstSeen) {
+		best.FirstSeen = seen
+	}
+	if seen.After(best.LastSeen) {
+		best.LastSeen = seen
+	}
+	return best
+}
+
+// Stories returns the stories with their labels, largest stories first and then the most recent ones
+func (c *Clusterer) Stories() []*Story {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stories := make([]*Story, len(c.stories))
+	copy(stories, c.stories)
+	for _, story := range stories {
+		story.Labels = c.labels(story)
+	}
+	sort.SliceStable(stories, func(i, j int) bool {
+		if stories[i].Size() != stories[j].Size() {
+			return stories[i].Size() > stories[j].Size()
+		}
+		return stories[i].LastSeen.After(stories[j].LastSeen)
+	})
+	return stories
+}
+
+// TopStories returns the n largest stories
+func (c *Clusterer) TopStories(n int) []*Story {
+	stories := c.Stories()
+	if n >= 0 && n < len(stories) {
+		stories = stories[:n]
+	}
+	return stories
+}
+
+// idf returns the smoothed inverse document frequency of the term
+func (c *Clusterer) idf(term string) float64 {
+	return math.Log(float64(1+c.docs)/float64(1+c.docFreqs[term])) + 1
+}
+
+// similarity returns the cosine similarity of the tf-idf vectors of the terms and the story centroid
+func (c *Clusterer) similarity(terms map[string]float64, story *Story) float64 {
+	size := float64(story.Size())
+	var dot, termNorm, storyNorm float64
+	for term, count := range terms {
+		idf := c.idf(term)
+		w := count * idf
+		termNorm += w * w
+		if storyCount, ok := story.termCounts[term]; ok {
+			dot += w * storyCount / size * idf
+		}
+	}
+	for term, count := range story.termCounts {
+		w := count / size * c.idf(term)
+		storyNorm += w * w
+	}
+	if termNorm == 0 || storyNorm == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(termNorm*storyNorm)
+}
+
+// labels returns the terms of the story with the highest tf-idf
+func (c *Clusterer) labels(story *Story) []string {
+	type scoredTerm struct {
+		term  string
+		score float64
+	}
+	scored := make([]scoredTerm, 0, len(story.termCounts))
+	for term, count := range story.termCounts {
+		scored = append(scored, scoredTerm{term, count * c.idf(term)})
+	}
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].term < scored[j].term
+	})
+	labels := make([]string, 0, c.labelCount)
+	for i := 0; i < len(scored) && i < c.labelCount; i++ {
+		labels = append(labels, c.surfaces[scored[i].term])
+	}
+	return labels
+}
+
+// newsTerms returns the stemmed term frequencies of the title, description and source content,
+// the first word of every new stem is recorded in surfaces
+func newsTerms(news *News, surfaces map[string]string) map[string]float64 {
+	terms := map[string]float64{}
+	add := func(text string, weight float64) {
+		for _, token := range tokenize(text) {
+			if _, ok := stopWords[token]; ok {
+				continue
+			}
+			if utf8.RuneCountInString(token) < 2 {
+				continue
+			}
+			term := stem(token)
+			if _, ok := surfaces[term]; !ok {
+				surfaces[term] = token
+			}
+			terms[term] += weight
+		}
+	}
+	add(dedupTitle(news), titleWeight)
+	add(news.Description, 1)
+	add(news.SourceContent, 1)
+	return terms
+}
+
+// stem strips common english suffixes so that e.g. "unveils" and "unveiled" match
+func stem(token string) string {
+	if len(token) <= 4 || utf8.RuneCountInString(token) != len(token) {
+		return token
+	}
+	for _, suffix := range []string{"ing", "ed", "s"} {
+		if strings.HasSuffix(token, suffix) && len(token)-len(suffix) >= 3 {
+			return strings.TrimSuffix(token, suffix)
+		}
+	}
+	return token
+}
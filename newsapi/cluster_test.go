@@ -0,0 +1,75 @@
+package newsapi
+
+import (
+	"testing"
+	"time"
+)
+
+func clusterTestNews() []*News {
+	at := func(hour int) *time.Time {
+		t := time.Date(2024, 5, 1, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+	return []*News{
+		{Title: "Apple unveils iPhone 16 with new AI features - Reuters", Provider: ProviderGoogle, PublishedParsed: at(8)},
+		{Title: "Central bank raises interest rates to fight inflation - AP News", Provider: ProviderGoogle, PublishedParsed: at(9)},
+		{Title: "iPhone 16 unveiled: Apple bets on AI features - The Verge", Provider: ProviderGoogle, PublishedParsed: at(10)},
+		{Title: "Volcano erupts in Iceland, flights cancelled - BBC", Provider: ProviderGoogle, PublishedParsed: at(11)},
+		{Title: "Apple's iPhone 16 AI features explained - Reuters", Provider: ProviderGoogle, PublishedParsed: at(12)},
+		{Title: "Interest rates raised again by the central bank - Bloomberg", Provider: ProviderGoogle, PublishedParsed: at(13)},
+	}
+}
+
+func TestClusterNews(t *testing.T) {
+	newsList := clusterTestNews()
+	stories := ClusterNews(newsList)
+	if len(stories) != 3 {
+		for _, story := range stories {
+			t.Logf("story %v: %d news", story.Labels, story.Size())
+		}
+		t.Fatalf("got %d stories, want 3", len(stories))
+	}
+
+	// the largest story first, then the most recent one
+	apple, bank, volcano := stories[0], stories[1], stories[2]
+	if apple.Size() != 3 || apple.News[0] != newsList[0] || apple.News[1] != newsList[2] || apple.News[2] != newsList[4] {
+		t.Errorf("apple story: got %d news", apple.Size())
+	}
+	if apple.PublisherCount() != 2 || apple.Publishers["Reuters"] != 2 {
+		t.Errorf("apple story: got publishers %v", apple.Publishers)
+	}
+	if !apple.FirstSeen.Equal(*newsList[0].PublishedParsed) || !apple.LastSeen.Equal(*newsList[4].PublishedParsed) {
+		t.Errorf("apple story: got first seen %v, last seen %v", apple.FirstSeen, apple.LastSeen)
+	}
+	if len(apple.Labels) != defaultLabelCount {
+		t.Errorf("apple story: got labels %v", apple.Labels)
+	}
+	if bank.Size() != 2 || bank.News[0] != newsList[1] {
+		t.Errorf("bank story: got %d news", bank.Size())
+	}
+	// an unrelated news stays alone
+	if volcano.Size() != 1 || volcano.News[0] != newsList[3] {
+		t.Errorf("volcano story: got %d news", volcano.Size())
+	}
+}
+
+func TestClustererThreshold(t *testing.T) {
+	newsList := clusterTestNews()
+	// every news is its own story when no similarity is enough
+	if stories := ClusterNews(newsList, WithClusterThreshold(1.1)); len(stories) != len(newsList) {
+		t.Errorf("threshold above 1: got %d stories, want %d", len(stories), len(newsList))
+	}
+
+	c := NewClusterer(WithLabelCount(1))
+	for _, news := range newsList {
+		c.Add(news)
+	}
+	// news without terms start their own story
+	c.Add(&News{Title: "a"})
+	if top := c.TopStories(1); len(top) != 1 || top[0].Size() != 3 || len(top[0].Labels) != 1 {
+		t.Errorf("got top stories %v", top)
+	}
+	if stories := c.Stories(); len(stories) != 4 {
+		t.Errorf("got %d stories, want 4", len(stories))
+	}
+}
@@ -0,0 +1,108 @@
+package newsapi
+
+import (
+	"strings"
+	"time"
+)
+
+var (
+	// dateLayouts are the fallback layouts tried when the feed parser cannot parse a date
+	dateLayouts = []string{
+		time.RFC1123Z,
+		time.RFC1123,
+		time.RFC3339,
+		time.RFC3339Nano,
+		time.RFC822Z,
+		time.RFC822,
+		time.RFC850,
+		time.ANSIC,
+		"Mon, 2 Jan 2006 15:04:05 -0700",
+		"Mon, 2 Jan 2006 15:04:05 MST",
+		"Mon, 2 Jan 2006 15:04 -0700",
+		"Mon, 2 Jan 2006 15:04 MST",
+		"2 Jan 2006 15:04:05 -0700",
+		"2 Jan 2006 15:04:05 MST",
+		"2 Jan 2006 15:04 MST",
+		"January 2, 2006 15:04 MST",
+		"January 2, 2006 3:04 PM MST",
+		"2006-01-02T15:04:05-0700",
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05 -0700",
+		"2006-01-02 15:04:05 MST",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006/01/02 15:04:05",
+		"2006/01/02 15:04",
+		"2006-01-02",
+		"2006/01/02",
+	}
+
+	// zoneOffsets are the offsets of common time zone abbreviations, in seconds east of UTC.
+	// time.Parse only knows the abbreviations of the local time zone.
+	zoneOffsets = map[string]int{
+		"UT":   0,
+		"UTC":  0,
+		"GMT":  0,
+		"Z":    0,
+		"EST":  -5 * 3600,
+		"EDT":  -4 * 3600,
+		"CST":  -6 * 3600,
+		"CDT":  -5 * 3600,
+		"MST":  -7 * 3600,
+		"MDT":  -6 * 3600,
+		"PST":  -8 * 3600,
+		"PDT":  -7 * 3600,
+		"AKST": -9 * 3600,
+		"AKDT": -8 * 3600,
+		"HST":  -10 * 3600,
+		"BST":  1 * 3600,
+		"IST":  5*3600 + 1800,
+		"WET":  0,
+		"WEST": 1 * 3600,
+		"CET":  1 * 3600,
+		"CEST": 2 * 3600,
+		"EET":  2 * 3600,
+		"EEST": 3 * 3600,
+		"MSK":  3 * 3600,
+		"SGT":  8 * 3600,
+		"HKT":  8 * 3600,
+		"JST":  9 * 3600,
+		"KST":  9 * 3600,
+		"AEST": 10 * 3600,
+		"AEDT": 11 * 3600,
+		"NZST": 12 * 3600,
+		"NZDT": 13 * 3600,
+	}
+)
+
+// ParseDate parses a date in one of the common feed layouts, it returns nil if the date cannot be parsed.
+// Dates without a time zone are treated as UTC.
+func ParseDate(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+		t = fixZoneOffset(t)
+		return &t
+	}
+	return nil
+}
+
+// fixZoneOffset applies the offset of a time zone abbreviation unknown to time.Parse
+func fixZoneOffset(t time.Time) time.Time {
+	name, offset := t.Zone()
+	if offset != 0 {
+		return t
+	}
+	zoneOffset, ok := zoneOffsets[name]
+	if !ok || zoneOffset == 0 {
+		return t
+	}
+	// reinterpret the wall clock in the right zone
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.FixedZone(name, zoneOffset))
+}
@@ -0,0 +1,63 @@
+package newsapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"Wed, 01 May 2024 14:30:00 GMT", want},
+		{"Wed, 01 May 2024 14:30:00 +0000", want},
+		{"Wed, 1 May 2024 16:30:00 +0200", want},
+		{"2024-05-01T14:30:00Z", want},
+		{"2024-05-01T22:30:00.000+08:00", want},
+		{"2024-05-01T10:30:00-0400", want},
+		{"2024-05-01 14:30:00", want},
+		{"2024/05/01 14:30", want},
+		{"May 1, 2024 2:30 PM UTC", want},
+		{"  01 May 24 14:30 UTC  ", want},
+		{"2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		// zone abbreviations unknown to time.Parse
+		{"Wed, 01 May 2024 10:30:00 EDT", want},
+		{"Wed, 01 May 2024 07:30:00 PDT", want},
+		{"Wed, 01 May 2024 16:30:00 CEST", want},
+		{"1 May 2024 23:30:00 JST", want},
+		{"Wed, 01 May 2024 20:00 IST", want},
+	}
+	for _, tt := range tests {
+		got := ParseDate(tt.value)
+		if got == nil {
+			t.Errorf("ParseDate(%q): got nil", tt.value)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseDate(%q): got %v, want %v", tt.value, got.UTC(), tt.want)
+		}
+	}
+	for _, value := range []string{"", "   ", "yesterday", "2024-13-45"} {
+		if got := ParseDate(value); got != nil {
+			t.Errorf("ParseDate(%q): got %v, want nil", value, got)
+		}
+	}
+}
+
+func TestFixZoneOffset(t *testing.T) {
+	// an unknown abbreviation keeps the time as parsed
+	parsed, err := time.Parse("2006-01-02 15:04 MST", "2024-05-01 14:30 XYZ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fixZoneOffset(parsed); !got.Equal(parsed) {
+		t.Errorf("unknown zone: got %v, want %v", got, parsed)
+	}
+	// a known offset is not changed
+	known := time.Date(2024, 5, 1, 14, 30, 0, 0, time.FixedZone("EST", -5*3600))
+	if got := fixZoneOffset(known); !got.Equal(known) {
+		t.Errorf("known offset: got %v, want %v", got, known)
+	}
+}
@@ -0,0 +1,358 @@
+package newsapi
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// titles are short, so a small change flips more bits than in a long document
+	defaultTitleDistance     = 8
+	defaultContentSimilarity = 0.8
+	defaultShingleSize       = 5
+	defaultMinHashSize       = 64
+)
+
+// SimHash returns the 64-bit simhash of the words of text
+func SimHash(text string) uint64 {
+	var weights [64]int
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return 0
+	}
+	for _, token := range tokens {
+		h := hashString(token)
+		for i := 0; i < 64; i++ {
+			if h&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+	var fingerprint uint64
+	for i := 0; i < 64; i++ {
+		if weights[i] > 0 {
+			fingerprint |= 1 << uint(i)
+		}
+	}
+	return fingerprint
+}
+
+// HammingDistance returns the number of different bits of two simhashes
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// MinHashSignature is the minhash signature of the shingles of a text
+type MinHashSignature []uint64
+
+// MinHash returns the signature of the shingles of shingleSize characters of text, using size hash functions
+func MinHash(text string, shingleSize, size int) MinHashSignature {
+	runes := []rune(strings.Join(strings.Fields(strings.ToLower(text)), " "))
+	if len(runes) == 0 || size <= 0 {
+		return nil
+	}
+	if shingleSize <= 0 || shingleSize > len(runes) {
+		shingleSize = len(runes)
+	}
+
+	signature := make(MinHashSignature, size)
+	for i := range signature {
+		signature[i] = ^uint64(0)
+	}
+	seen := make(map[uint64]struct{})
+	for i := 0; i+shingleSize <= len(runes); i++ {
+		h := hashString(string(runes[i : i+shingleSize]))
+		if _, ok := seen[h]; ok {
+			continue
+		}
+		seen[h] = struct{}{}
+		for j := range signature {
+			a, b := minHashCoefficients(j)
+			if v := a*h + b; v < signature[j] {
+				signature[j] = v
+			}
+		}
+	}
+	return signature
+}
+
+// Similarity estimates the jaccard similarity of the shingles of two signatures
+func (s MinHashSignature) Similarity(other MinHashSignature) float64 {
+	if len(s) == 0 || len(s) != len(other) {
+		return 0
+	}
+	equal := 0
+	for i := range s {
+		if s[i] == other[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(s))
+}
+
+// minHashCoefficients returns the deterministic coefficients of the i-th hash function
+func minHashCoefficients(i int) (a, b uint64) {
+	return splitMix64(uint64(2*i+1)) | 1, splitMix64(uint64(2*i + 2))
+}
+
+func splitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// tokenize splits text into lower case words, runs of CJK characters are split into bigrams
+func tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	var cjk []rune
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			tokens = append(tokens, string(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			tokens = append(tokens, string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return tokens
+}
+
+// isCJK checks whether r is a chinese, japanese or korean character
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// dedupTitle returns the title without the " - Publisher" suffix google news appends
+func dedupTitle(news *News) string {
+	title := news.Title
+	if news.Provider == ProviderGoogle {
+		if i := strings.LastIndex(title, " - "); i > 0 {
+			title = title[:i]
+		}
+	}
+	return title
+}
+
+// DuplicateGroup is a group of near-duplicated news
+type DuplicateGroup struct {
+	// Canonical is the news representing the group
+	Canonical *News
+	// Members are all news of the group, including the canonical one
+	Members []*News
+
+	fingerprints []*fingerprint
+}
+
+type fingerprint struct {
+	title   uint64
+	content MinHashSignature
+}
+
+// Deduplicator groups near-duplicated news by the simhash of their titles and the minhash of their contents
+type Deduplicator struct {
+	mu sync.Mutex
+
+	titleDistance     int
+	contentSimilarity float64
+	shingleSize       int
+	minHashSize       int
+	better            func(a, b *News) bool
+
+	groups []*DuplicateGroup
+}
+
+type DeduplicatorOption func(*Deduplicator)
+
+// WithTitleDistance sets the maximal hamming distance of the title simhashes of duplicates, a negative distance disables title matching
+func WithTitleDistance(distance int) DeduplicatorOption {
+	return func(d *Deduplicator) {
+		d.titleDistance = distance
+	}
+}
+
+// WithContentSimilarity sets the minimal minhash similarity of the source contents of duplicates, 0 disables content matching
+func WithContentSimilarity(similarity float64) DeduplicatorOption {
+	return func(d *Deduplicator) {
+		d.contentSimilarity = similarity
+	}
+}
+
+// WithShingleSize sets the number of characters of the content shingles
+func WithShingleSize(size int) DeduplicatorOption {
+	return func(d *Deduplicator) {
+		if size > 0 {
+			d.shingleSize = size
+		}
+	}
+}
+
+// WithMinHashSize sets the number of hash functions of the content minhash
+func WithMinHashSize(size int) DeduplicatorOption {
+	return func(d *Deduplicator) {
+		if size > 0 {
+			d.minHashSize = size
+		}
+	}
+}
+
+// WithCanonicalSelector sets how the canonical news of a group is picked, better reports whether a should replace b
+func WithCanonicalSelector(better func(a, b *News) bool) DeduplicatorOption {
+	return func(d *Deduplicator) {
+		d.better = better
+	}
+}
+
+// NewDeduplicator returns a deduplicator which groups news incrementally with Add
+func NewDeduplicator(options ...DeduplicatorOption) *Deduplicator {
+	d := &Deduplicator{
+		titleDistance:     defaultTitleDistance,
+		contentSimilarity: defaultContentSimilarity,
+		shingleSize:       defaultShingleSize,
+		minHashSize:       defaultMinHashSize,
+		better:            betterCanonical,
+	}
+	for _, option := range options {
+		option(d)
+	}
+	return d
+}
+
+// Deduplicate groups the news in one batch
+func Deduplicate(newsList []*News, options ...DeduplicatorOption) []*DuplicateGroup {
+	d := NewDeduplicator(options...)
+	for _, news := range newsList {
+		d.Add(news)
+	}
+	return d.Groups()
+}
+
+// betterCanonical prefers enriched news, then the earliest published one
+func betterCanonical(a, b *News) bool {
+	if (a.SourceContent != "") != (b.SourceContent != "") {
+		return a.SourceContent != ""
+	}
+	return dateBefore(a.PublishedParsed, b.PublishedParsed)
+}
+
+// Add adds the news to the group of its near-duplicates, or to a new group.
+// It reports whether the news started a new group.
+// A news matching several groups merges them into one.
+func (d *Deduplicator) Add(news *News) (group *DuplicateGroup, isNew bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	fp := d.fingerprint(news)
+	var matched []int
+	for i, g := range d.groups {
+		if d.matchGroup(fp, g) {
+			matched = append(matched, i)
+		}
+	}
+
+	if len(matched) == 0 {
+		group = &DuplicateGroup{
+			Canonical:    news,
+			Members:      []*News{news},
+			fingerprints: []*fingerprint{fp},
+		}
+		d.groups = append(d.groups, group)
+		return group, true
+	}
+
+	group = d.groups[matched[0]]
+	group.add(news, fp, d.better)
+	// merge the other matched groups into the first one
+	for k := len(matched) - 1; k > 0; k-- {
+		other := d.groups[matched[k]]
+		for i, member := range other.Members {
+			group.add(member, other.fingerprints[i], d.better)
+		}
+		d.groups = append(d.groups[:matched[k]], d.groups[matched[k]+1:]...)
+	}
+	return group, false
+}
+
+// Groups returns the groups in the order they were created
+func (d *Deduplicator) Groups() []*DuplicateGroup {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	groups := make([]*DuplicateGroup, len(d.groups))
+	copy(groups, d.groups)
+	return groups
+}
+
+// Canonicals returns the canonical news of every group
+func (d *Deduplicator) Canonicals() []*News {
+	groups := d.Groups()
+	newsList := make([]*News, 0, len(groups))
+	for _, g := range groups {
+		newsList = append(newsList, g.Canonical)
+	}
+	return newsList
+}
+
+func (d *Deduplicator) fingerprint(news *News) *fingerprint {
+	fp := &fingerprint{}
+	if title := dedupTitle(news); title != "" {
+		fp.title = SimHash(title)
+	}
+	if news.SourceContent != "" && d.contentSimilarity > 0 {
+		fp.content = MinHash(news.SourceContent, d.shingleSize, d.minHashSize)
+	}
+	return fp
+}
+
+// matchGroup checks whether the fingerprint is near any member of the group
+func (d *Deduplicator) matchGroup(fp *fingerprint, g *DuplicateGroup) bool {
+	for _, other := range g.fingerprints {
+		if d.titleDistance >= 0 && fp.title != 0 && other.title != 0 && HammingDistance(fp.title, other.title) <= d.titleDistance {
+			return true
+		}
+		if fp.content != nil && other.content != nil && fp.content.Similarity(other.content) >= d.contentSimilarity {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *DuplicateGroup) add(news *News, fp *fingerprint, better func(a, b *News) bool) {
+	g.Members = append(g.Members, news)
+	g.fingerprints = append(g.fingerprints, fp)
+	if better(news, g.Canonical) {
+		g.Canonical = news
+	}
+}
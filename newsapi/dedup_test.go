@@ -0,0 +1,122 @@
+package newsapi
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	dedupTestContent = "The company said on Tuesday that revenue for the third quarter rose 12 percent to a record, " +
+		"driven by demand for artificial intelligence chips from data centers around the world."
+	dedupTestRewrite = "The company said on Tuesday that revenue for the third quarter rose 12 percent to a record, " +
+		"driven by strong demand for artificial intelligence chips from data centers around the world."
+	dedupTestOther = "Heavy rain flooded the streets of the capital on Monday, forcing schools to close " +
+		"and cutting power to thousands of homes across several districts."
+)
+
+func TestSimHash(t *testing.T) {
+	title := "Apple unveils new iPhone with faster chip and better camera"
+	if SimHash("") != 0 || SimHash(" - ,. ") != 0 {
+		t.Error("text without words: got a non zero simhash")
+	}
+	if SimHash(title) != SimHash("APPLE unveils new iPhone, with faster chip and better camera!") {
+		t.Error("case and punctuation changed the simhash")
+	}
+	near := HammingDistance(SimHash(title), SimHash("Apple unveils the new iPhone with a faster chip and better camera"))
+	far := HammingDistance(SimHash(title), SimHash("Central bank raises interest rates again"))
+	if near > defaultTitleDistance || far <= defaultTitleDistance {
+		t.Errorf("got distance %d to a near-duplicate and %d to another title", near, far)
+	}
+}
+
+func TestMinHash(t *testing.T) {
+	if MinHash("", defaultShingleSize, defaultMinHashSize) != nil || MinHash("  ", defaultShingleSize, defaultMinHashSize) != nil {
+		t.Error("empty text: got a signature")
+	}
+	signature := MinHash(dedupTestContent, defaultShingleSize, defaultMinHashSize)
+	if len(signature) != defaultMinHashSize {
+		t.Fatalf("got %d hashes, want %d", len(signature), defaultMinHashSize)
+	}
+	if similarity := signature.Similarity(MinHash(dedupTestContent, defaultShingleSize, defaultMinHashSize)); similarity != 1 {
+		t.Errorf("same text: got similarity %v", similarity)
+	}
+	if similarity := signature.Similarity(MinHash(dedupTestRewrite, defaultShingleSize, defaultMinHashSize)); similarity < defaultContentSimilarity {
+		t.Errorf("near-duplicate text: got similarity %v", similarity)
+	}
+	if similarity := signature.Similarity(MinHash(dedupTestOther, defaultShingleSize, defaultMinHashSize)); similarity > 0.2 {
+		t.Errorf("other text: got similarity %v", similarity)
+	}
+	if similarity := signature.Similarity(MinHash(dedupTestContent, defaultShingleSize, 32)); similarity != 0 {
+		t.Errorf("signatures of different sizes: got similarity %v", similarity)
+	}
+	if similarity := signature.Similarity(nil); similarity != 0 {
+		t.Errorf("empty signature: got similarity %v", similarity)
+	}
+}
+
+func TestDeduplicate(t *testing.T) {
+	published := func(hour int) *time.Time {
+		t := time.Date(2024, 5, 1, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+	newsList := []*News{
+		{Title: "Apple unveils new iPhone with faster chip and better camera - Reuters", Provider: ProviderGoogle, PublishedParsed: published(9)},
+		{Title: "Central bank raises interest rates again - AP News", Provider: ProviderGoogle, PublishedParsed: published(8)},
+		{Title: "Apple unveils the new iPhone with a faster chip and better camera - The Verge", Provider: ProviderGoogle, PublishedParsed: published(10)},
+		{Title: "Apple's quarter beats estimates", SourceContent: dedupTestContent, PublishedParsed: published(11)},
+		{Title: "Record revenue at Apple", SourceContent: dedupTestRewrite, PublishedParsed: published(12)},
+		{Title: "", PublishedParsed: published(7)},
+		{Title: "", PublishedParsed: published(6)},
+	}
+	groups := Deduplicate(newsList)
+	// the titles, the contents, and the two news without title or content apart
+	if len(groups) != 5 {
+		t.Fatalf("got %d groups, want 5", len(groups))
+	}
+	if len(groups[0].Members) != 2 || groups[0].Canonical != newsList[0] {
+		t.Errorf("near-duplicate titles: got %d members, canonical %q", len(groups[0].Members), groups[0].Canonical.Title)
+	}
+	if len(groups[1].Members) != 1 {
+		t.Errorf("another title: got %d members", len(groups[1].Members))
+	}
+	if len(groups[2].Members) != 2 || groups[2].Canonical != newsList[3] {
+		t.Errorf("near-duplicate contents: got %d members, canonical %q", len(groups[2].Members), groups[2].Canonical.Title)
+	}
+
+	// the thresholds disable the title and content matching
+	if groups := Deduplicate(newsList[:3], WithTitleDistance(-1)); len(groups) != 3 {
+		t.Errorf("without title matching: got %d groups, want 3", len(groups))
+	}
+	if groups := Deduplicate(newsList[3:5], WithContentSimilarity(0)); len(groups) != 2 {
+		t.Errorf("without content matching: got %d groups, want 2", len(groups))
+	}
+	if groups := Deduplicate(newsList[3:5], WithContentSimilarity(1)); len(groups) != 2 {
+		t.Errorf("with an exact content similarity: got %d groups, want 2", len(groups))
+	}
+	if groups := Deduplicate(newsList[:3], WithTitleDistance(64)); len(groups) != 1 {
+		t.Errorf("with the largest title distance: got %d groups, want 1", len(groups))
+	}
+}
+
+func TestDeduplicatorAdd(t *testing.T) {
+	d := NewDeduplicator()
+	if _, isNew := d.Add(&News{Title: "Apple unveils new iPhone with faster chip and better camera"}); !isNew {
+		t.Error("first news: got an existing group")
+	}
+	if _, isNew := d.Add(&News{Title: "Record revenue at Apple", SourceContent: dedupTestContent}); !isNew {
+		t.Error("another title and content: got an existing group")
+	}
+	// a news matching the title of a group and the content of another merges them
+	enriched := &News{Title: "Apple unveils the new iPhone with a faster chip and better camera", SourceContent: dedupTestRewrite}
+	group, isNew := d.Add(enriched)
+	if isNew {
+		t.Error("near-duplicate: got a new group")
+	}
+	if groups := d.Groups(); len(groups) != 1 || len(group.Members) != 3 {
+		t.Fatalf("got %d groups, want the merged one", len(groups))
+	}
+	// the enriched news replaced the canonical of the first group, which stays the canonical of the merged group
+	if canonicals := d.Canonicals(); canonicals[0] != enriched {
+		t.Errorf("got canonical %q", canonicals[0].Title)
+	}
+}
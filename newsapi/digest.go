@@ -0,0 +1,437 @@
+package newsapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+)
+
+const (
+	defaultDigestSubject      = "News digest"
+	defaultDigestSectionLimit = 10
+)
+
+var (
+	// DefaultDigestHTMLTemplate renders a Digest as html
+	DefaultDigestHTMLTemplate = htmltemplate.Must(htmltemplate.New("digest").Funcs(digestFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Subject}}</title></head>
+<body style="font-family: Arial, sans-serif; max-width: 640px; margin: 0 auto;">
+<h1 style="font-size: 22px;">{{.Subject}}</h1>
+<p style="color: #666;">{{.Date.Format "Monday, 2 January 2006"}}</p>
+{{range .Sections}}
+<h2 style="font-size: 18px; border-bottom: 1px solid #ddd;">{{.Title}}</h2>
+<table cellpadding="0" cellspacing="0" border="0" width="100%">
+{{range .News}}
+<tr>
+<td style="padding: 8px 0; vertical-align: top;">
+<a href="{{link .}}" style="font-size: 16px; color: #1a0dab; text-decoration: none;">{{title .}}</a>
+<div style="color: #666; font-size: 13px;">{{with publisher .}}{{.}}{{end}}{{with .PublishedParsed}} · {{.Format "15:04 Jan 2"}}{{end}}</div>
+{{with .SourceDescription}}<div style="font-size: 14px;">{{.}}</div>{{end}}
+</td>
+{{with image .}}<td style="padding: 8px 0 8px 12px; vertical-align: top;" width="120"><img src="{{.}}" width="120" alt=""></td>{{end}}
+</tr>
+{{end}}
+</table>
+{{end}}
+</body>
+</html>
+`))
+
+	// DefaultDigestTextTemplate renders a Digest as plain text
+	DefaultDigestTextTemplate = texttemplate.Must(texttemplate.New("digest").Funcs(texttemplate.FuncMap(digestFuncs)).Parse(`{{.Subject}}
+{{.Date.Format "Monday, 2 January 2006"}}
+{{range .Sections}}
+== {{.Title}} ==
+{{range .News}}
+* {{title .}}{{with publisher .}} ({{.}}){{end}}
+  {{link .}}
+{{end}}{{end}}`))
+
+	digestFuncs = htmltemplate.FuncMap{
+		"title":     dedupTitle,
+		"link":      notificationLink,
+		"image":     notificationImage,
+		"publisher": (*News).Publisher,
+	}
+)
+
+// DigestSection is a section of a digest, the news of one query
+type DigestSection struct {
+	Title string
+	News  []*News
+}
+
+// Digest is a digest of the news of several queries
+type Digest struct {
+	Subject  string
+	Date     time.Time
+	Sections []DigestSection
+
+	htmlTemplate *htmltemplate.Template
+	textTemplate *texttemplate.Template
+}
+
+// DigestBuilder builds digests by running queries, one section per query
+type DigestBuilder struct {
+	api            NewsApi
+	queries        []Query
+	subject        string
+	sectionLimit   int
+	sourceContents bool
+	htmlTemplate   *htmltemplate.Template
+	textTemplate   *texttemplate.Template
+}
+
+type DigestOption func(*DigestBuilder)
+
+// WithDigestSubject sets the subject of the digest
+func WithDigestSubject(subject string) DigestOption {
+	return func(b *DigestBuilder) {
+		b.subject = subject
+	}
+}
+
+// WithSectionLimit sets the maximal number of news of a section
+func WithSectionLimit(limit int) DigestOption {
+	return func(b *DigestBuilder) {
+		b.sectionLimit = limit
+	}
+}
+
+// WithDigestSourceContents fetches the source contents of the news, for their thumbnails and site names
+func WithDigestSourceContents() DigestOption {
+	return func(b *DigestBuilder) {
+		b.sourceContents = true
+	}
+}
+
+// WithDigestHTMLTemplate sets the html template, executed with a *Digest
+func WithDigestHTMLTemplate(tmpl *htmltemplate.Template) DigestOption {
+	return func(b *DigestBuilder) {
+		b.htmlTemplate = tmpl
+	}
+}
+
+// WithDigestTextTemplate sets the plain text template, executed with a *Digest
+func WithDigestTextTemplate(tmpl *texttemplate.Template) DigestOption {
+	return func(b *DigestBuilder) {
+		b.textTemplate = tmpl
+	}
+}
+
+// NewDigestBuilder returns a digest builder running the queries with api
+func NewDigestBuilder(api NewsApi, options ...DigestOption) *DigestBuilder {
+	b := &DigestBuilder{
+		api:          api,
+		subject:      defaultDigestSubject,
+		sectionLimit: defaultDigestSectionLimit,
+		htmlTemplate: DefaultDigestHTMLTemplate,
+		textTemplate: DefaultDigestTextTemplate,
+	}
+	for _, option := range options {
+		option(b)
+	}
+	return b
+}
+
+// AddQuery adds queries to the digest, each query is a section titled by its name
+func (b *DigestBuilder) AddQuery(queries ...Query) error {
+	for _, q := range queries {
+		if err := q.validate(); err != nil {
+			return err
+		}
+		for _, existing := range b.queries {
+			if existing.Name == q.Name {
+				return fmt.Errorf("%w: %s", ErrDuplicateQueryName, q.Name)
+			}
+		}
+		b.queries = append(b.queries, q)
+	}
+	return nil
+}
+
+// Build runs the queries and returns the digest.
+// A news found by several queries only appears in the first section, and empty sections are left out.
+// When only some queries fail, their sections are left out and the digest is returned along with a QueryErrors.
+func (b *DigestBuilder) Build() (*Digest, error) {
+	if len(b.queries) == 0 {
+		return nil, ErrEmptyQuery
+	}
+	results := make([][]*News, len(b.queries))
+	errs := make([]error, len(b.queries))
+	var wg sync.WaitGroup
+	for i, q := range b.queries {
+		wg.Add(1)
+		go func(i int, q Query) {
+			defer wg.Done()
+			results[i], errs[i] = q.fetch(b.api)
+		}(i, q)
+	}
+	wg.Wait()
+
+	var queryErrs QueryErrors
+	for i, q := range b.queries {
+		if errs[i] != nil {
+			queryErrs = append(queryErrs, &QueryError{Query: q.Name, Err: errs[i]})
+		}
+	}
+	if len(queryErrs) == len(b.queries) {
+		return nil, queryErrs
+	}
+
+	digest := &Digest{
+		Subject:      b.subject,
+		Date:         time.Now(),
+		htmlTemplate: b.htmlTemplate,
+		textTemplate: b.textTemplate,
+	}
+	seen := map[string]bool{}
+	var all []*News
+	for i, q := range b.queries {
+		if errs[i] != nil {
+			continue
+		}
+		section := DigestSection{Title: q.Name}
+		for _, news := range results[i] {
+			if b.sectionLimit > 0 && len(section.News) >= b.sectionLimit {
+				break
+			}
+			key := news.dedupKey()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			section.News = append(section.News, news)
+		}
+		if len(section.News) > 0 {
+			digest.Sections = append(digest.Sections, section)
+			all = append(all, section.News...)
+		}
+	}
+	if b.sourceContents {
+		b.api.FetchSourceContents(all)
+	}
+	if len(queryErrs) > 0 {
+		return digest, queryErrs
+	}
+	return digest, nil
+}
+
+// Schedule builds and sends a digest every day at the time of day at, e.g. 8*time.Hour, in local time.
+// A failed digest is reported to errorHandler, if any, and retried the next day.
+// A digest with failed queries is still sent, and the QueryErrors are reported.
+// It returns ctx.Err() when ctx is done.
+func (b *DigestBuilder) Schedule(ctx context.Context, at time.Duration, sender MailSender, from string, to []string, errorHandler func(err error)) error {
+	if at < 0 || at >= 24*time.Hour {
+		return ErrInvalidTimeOfDay
+	}
+	if len(to) == 0 {
+		return ErrNoRecipients
+	}
+	for {
+		timer := time.NewTimer(time.Until(nextDigestTime(time.Now(), at)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		digest, err := b.Build()
+		if digest != nil {
+			if sendErr := digest.Send(sender, from, to); sendErr != nil {
+				err = sendErr
+			}
+		}
+		if err != nil && errorHandler != nil {
+			errorHandler(err)
+		}
+	}
+}
+
+// nextDigestTime returns the next time after now at the time of day at.
+// The wall clock is used, so that the time of day is kept on the days of daylight saving changes.
+func nextDigestTime(now time.Time, at time.Duration) time.Time {
+	y, m, d := now.Date()
+	hour, min, sec := int(at/time.Hour), int(at%time.Hour/time.Minute), int(at%time.Minute/time.Second)
+	next := time.Date(y, m, d, hour, min, sec, 0, now.Location())
+	if !next.After(now) {
+		next = time.Date(y, m, d+1, hour, min, sec, 0, now.Location())
+	}
+	return next
+}
+
+// RenderHTML renders the digest with its html template
+func (d *Digest) RenderHTML() (string, error) {
+	var buf bytes.Buffer
+	if err := d.htmlTemplate.Execute(&buf, d); err != nil {
+		return "", fmt.Errorf("error rendering html: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// RenderText renders the digest with its plain text template
+func (d *Digest) RenderText() (string, error) {
+	var buf bytes.Buffer
+	if err := d.textTemplate.Execute(&buf, d); err != nil {
+		return "", fmt.Errorf("error rendering text: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// parseAddresses parses the sender and recipients of a message, e.g. "News <news@example.com>"
+func parseAddresses(from string, to []string) (*mail.Address, []*mail.Address, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %q: %s", ErrInvalidAddress, from, err)
+	}
+	recipients := make([]*mail.Address, 0, len(to))
+	for _, addr := range to {
+		recipient, err := mail.ParseAddress(addr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %q: %s", ErrInvalidAddress, addr, err)
+		}
+		recipients = append(recipients, recipient)
+	}
+	return sender, recipients, nil
+}
+
+// Message returns the digest as a multipart/alternative email with a plain text and an html part.
+// The addresses are parsed and formatted again, so that they cannot inject headers.
+func (d *Digest) Message(from string, to []string) ([]byte, error) {
+	sender, recipients, err := parseAddresses(from, to)
+	if err != nil {
+		return nil, err
+	}
+	text, err := d.RenderText()
+	if err != nil {
+		return nil, err
+	}
+	html, err := d.RenderHTML()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	body := multipart.NewWriter(&buf)
+	header := textproto.MIMEHeader{}
+	header.Set("From", sender.String())
+	toList := make([]string, 0, len(recipients))
+	for _, recipient := range recipients {
+		toList = append(toList, recipient.String())
+	}
+	header.Set("To", strings.Join(toList, ", "))
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", d.Subject))
+	header.Set("Date", d.Date.Format(time.RFC1123Z))
+	header.Set("MIME-Version", "1.0")
+	header.Set("Content-Type", "multipart/alternative; boundary="+body.Boundary())
+
+	var msg bytes.Buffer
+	for _, key := range []string{"From", "To", "Subject", "Date", "MIME-Version", "Content-Type"} {
+		fmt.Fprintf(&msg, "%s: %s\r\n", key, header.Get(key))
+	}
+	msg.WriteString("\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error writing message: %w", err)
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("error writing message: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("error writing message: %w", err)
+		}
+	}
+	if err := body.Close(); err != nil {
+		return nil, fmt.Errorf("error writing message: %w", err)
+	}
+	msg.Write(buf.Bytes())
+	return msg.Bytes(), nil
+}
+
+// Send sends the digest email with the sender
+func (d *Digest) Send(sender MailSender, from string, to []string) error {
+	if len(to) == 0 {
+		return ErrNoRecipients
+	}
+	msg, err := d.Message(from, to)
+	if err != nil {
+		return err
+	}
+	// the envelope only takes the bare addresses
+	envelopeFrom, recipients, err := parseAddresses(from, to)
+	if err != nil {
+		return err
+	}
+	envelopeTo := make([]string, 0, len(recipients))
+	for _, recipient := range recipients {
+		envelopeTo = append(envelopeTo, recipient.Address)
+	}
+	return sender.SendMail(envelopeFrom.Address, envelopeTo, msg)
+}
+
+// MailSender sends email messages
+type MailSender interface {
+	SendMail(from string, to []string, msg []byte) error
+}
+
+var (
+	_ MailSender = (*SMTPSender)(nil)
+	_ MailSender = (*DryRunSender)(nil)
+)
+
+// SMTPSender sends email over smtp
+type SMTPSender struct {
+	// Addr is the address of the smtp server, e.g. "smtp.example.com:587"
+	Addr string
+	// Auth is the authentication, nil for none
+	Auth smtp.Auth
+}
+
+func (s *SMTPSender) SendMail(from string, to []string, msg []byte) error {
+	if err := smtp.SendMail(s.Addr, s.Auth, from, to, msg); err != nil {
+		return fmt.Errorf("error sending mail: %w", err)
+	}
+	return nil
+}
+
+// DryRunSender writes the messages to .eml files in Dir instead of sending them
+type DryRunSender struct {
+	Dir string
+}
+
+func (s *DryRunSender) SendMail(from string, to []string, msg []byte) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+	name := fmt.Sprintf("digest-%s.eml", time.Now().Format("20060102-150405.000000000"))
+	if err := os.WriteFile(filepath.Join(s.Dir, name), msg, 0o644); err != nil {
+		return fmt.Errorf("error writing mail: %w", err)
+	}
+	return nil
+}
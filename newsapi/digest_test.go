@@ -0,0 +1,235 @@
+package newsapi
+
+import (
+	"errors"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zhima-Mochi/newsApi-go/newsapi/newsapitest"
+)
+
+func newTestDigest(t *testing.T) (*Digest, *newsapitest.Server) {
+	t.Helper()
+	server := newsapitest.NewServer()
+	t.Cleanup(server.Close)
+	articles := testArticles(server)
+	server.SetTopicNews("BUSINESS", newsapitest.Fixture{Articles: articles})
+	server.SetSearchNews("chips", newsapitest.Fixture{Articles: articles[:1]})
+
+	builder := NewDigestBuilder(newTestNewsApi(server), WithDigestSubject("Daily news"))
+	if err := builder.AddQuery(
+		NewSearchQuery("Chips", "chips"),
+		NewTopicQuery("Business", TopicBusiness),
+		NewSearchQuery("Empty", "nothing"),
+	); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return digest, server
+}
+
+func TestDigestBuild(t *testing.T) {
+	digest, _ := newTestDigest(t)
+	// the news of the first section is left out of the second one, and the empty section is left out
+	if len(digest.Sections) != 2 || digest.Sections[0].Title != "Chips" || len(digest.Sections[0].News) != 1 ||
+		digest.Sections[1].Title != "Business" || len(digest.Sections[1].News) != 1 {
+		t.Fatalf("got sections %+v", digest.Sections)
+	}
+	html, err := digest.RenderHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, "<h2") || !strings.Contains(html, ">Chip exports rise</a>") {
+		t.Errorf("got html %s", html)
+	}
+	text, err := digest.RenderText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "== Business ==") || !strings.Contains(text, "* Markets close higher (AP News)") {
+		t.Errorf("got text %s", text)
+	}
+}
+
+func TestDigestBuildFailedQuery(t *testing.T) {
+	server := newsapitest.NewServer()
+	defer server.Close()
+	server.SetTopicNews("BUSINESS", newsapitest.Fixture{Articles: testArticles(server)})
+	server.SetSearchNews("chips", newsapitest.Fixture{Status: 503})
+
+	builder := NewDigestBuilder(newTestNewsApi(server))
+	if err := builder.AddQuery(NewSearchQuery("Chips", "chips"), NewTopicQuery("Business", TopicBusiness)); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := builder.Build()
+	var queryErrs QueryErrors
+	if !errors.As(err, &queryErrs) || len(queryErrs) != 1 || queryErrs[0].Query != "Chips" {
+		t.Fatalf("got %v, want the error of the Chips query", err)
+	}
+	if digest == nil || len(digest.Sections) != 1 || digest.Sections[0].Title != "Business" {
+		t.Fatalf("got digest %+v", digest)
+	}
+
+	if err := builder.AddQuery(Query{Name: "Invalid"}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("query without fetch: got %v, want ErrInvalidQuery", err)
+	}
+}
+
+func TestDigestMessage(t *testing.T) {
+	digest, _ := newTestDigest(t)
+	msg, err := digest.Message("News Bot <news@example.com>", []string{"team@example.com", "Jöhn <john@example.com>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, _, _ := strings.Cut(string(msg), "\r\n\r\n")
+	for _, want := range []string{
+		"From: \"News Bot\" <news@example.com>\r\n",
+		"To: <team@example.com>, =?utf-8?q?J=C3=B6hn?= <john@example.com>\r\n",
+		"Subject: Daily news\r\n",
+		"Content-Type: multipart/alternative; boundary=",
+	} {
+		if !strings.Contains(header+"\r\n", want) {
+			t.Errorf("header %q does not contain %q", header, want)
+		}
+	}
+	if !strings.Contains(string(msg), "Content-Type: text/html; charset=utf-8") {
+		t.Error("the message has no html part")
+	}
+
+	for _, from := range []string{"news@example.com\r\nBcc: evil@example.com", "\"News\r\nBcc: evil@example.com\" <news@example.com>"} {
+		if _, err := digest.Message(from, []string{"team@example.com"}); !errors.Is(err, ErrInvalidAddress) {
+			t.Errorf("from %q: got %v, want ErrInvalidAddress", from, err)
+		}
+	}
+	if _, err := digest.Message("news@example.com", []string{"team@example.com\nBcc: evil@example.com"}); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("to with a header: got %v, want ErrInvalidAddress", err)
+	}
+}
+
+func TestDryRunSender(t *testing.T) {
+	digest, _ := newTestDigest(t)
+	dir := filepath.Join(t.TempDir(), "digests")
+	if err := digest.Send(&DryRunSender{Dir: dir}, "news@example.com", []string{"team@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("got files %v, want 1", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	if !strings.HasPrefix(string(data), "From: <news@example.com>\r\n") {
+		t.Errorf("got %q", data[:40])
+	}
+	if err := digest.Send(&DryRunSender{Dir: dir}, "news@example.com", nil); !errors.Is(err, ErrNoRecipients) {
+		t.Errorf("no recipients: got %v, want ErrNoRecipients", err)
+	}
+}
+
+// smtpTestServer is a minimal smtp server accepting one message
+type smtpTestServer struct {
+	listener net.Listener
+	from     string
+	to       []string
+	data     string
+	done     chan struct{}
+}
+
+func newSMTPTestServer(t *testing.T) *smtpTestServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpTestServer{listener: listener, done: make(chan struct{})}
+	t.Cleanup(func() { listener.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *smtpTestServer) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			text.PrintfLine("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+			text.PrintfLine("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.to = append(s.to, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			text.PrintfLine("250 OK")
+		case command == "DATA":
+			text.PrintfLine("354 go ahead")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.data = string(data)
+			text.PrintfLine("250 OK")
+		case command == "QUIT":
+			text.PrintfLine("221 bye")
+			return
+		default:
+			text.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPSender(t *testing.T) {
+	digest, _ := newTestDigest(t)
+	server := newSMTPTestServer(t)
+	sender := &SMTPSender{Addr: server.listener.Addr().String()}
+	if err := digest.Send(sender, "News Bot <news@example.com>", []string{"Team <team@example.com>"}); err != nil {
+		t.Fatal(err)
+	}
+	<-server.done
+	// the envelope has the bare addresses, and the headers the named ones
+	if server.from != "news@example.com" || len(server.to) != 1 || server.to[0] != "team@example.com" {
+		t.Errorf("got envelope from %q to %q", server.from, server.to)
+	}
+	if !strings.Contains(server.data, "To: \"Team\" <team@example.com>") {
+		t.Errorf("got message %q", server.data)
+	}
+}
+
+func TestNextDigestTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	tests := []struct {
+		now  time.Time
+		at   time.Duration
+		want time.Time
+	}{
+		{time.Date(2024, 5, 1, 7, 0, 0, 0, newYork), 8 * time.Hour, time.Date(2024, 5, 1, 8, 0, 0, 0, newYork)},
+		{time.Date(2024, 5, 1, 8, 0, 0, 0, newYork), 8 * time.Hour, time.Date(2024, 5, 2, 8, 0, 0, 0, newYork)},
+		// the days of daylight saving changes are 23 and 25 hours long
+		{time.Date(2024, 3, 10, 0, 30, 0, 0, newYork), 8*time.Hour + 30*time.Minute, time.Date(2024, 3, 10, 8, 30, 0, 0, newYork)},
+		{time.Date(2024, 11, 2, 9, 0, 0, 0, newYork), 8 * time.Hour, time.Date(2024, 11, 3, 8, 0, 0, 0, newYork)},
+	}
+	for _, tt := range tests {
+		if got := nextDigestTime(tt.now, tt.at); !got.Equal(tt.want) {
+			t.Errorf("nextDigestTime(%v, %v): got %v, want %v", tt.now, tt.at, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package newsapi
+
+import (
+	"strings"
+)
+
+// Edition is a google news edition, a supported pair of language and location
+type Edition struct {
+	// Language is the content language used in ceid, e.g. LanguagePortugueseBrasil
+	Language string `json:"language"`
+	// Location is the country code used in gl and ceid, e.g. LocationBrazil
+	Location string `json:"location"`
+	// HL is the interface language sent as hl, e.g. "pt-BR"
+	HL string `json:"hl,omitempty"`
+}
+
+// CEID returns the ceid query parameter of the edition
+func (e Edition) CEID() string {
+	return e.Location + ":" + e.Language
+}
+
+// String returns the edition as "Location:Language"
+func (e Edition) String() string {
+	return e.CEID()
+}
+
+// Editions lists every edition supported by google news.
+// The first edition of each language is its default edition.
+var Editions = []Edition{
+	// English
+	{LanguageEnglish, LocationUnitedStates, "en-US"},
+	{LanguageEnglish, LocationAustralia, "en-AU"},
+	{LanguageEnglish, LocationBotswana, "en-BW"},
+	{LanguageEnglish, LocationCanada, "en-CA"},
+	{LanguageEnglish, LocationEthiopia, "en-ET"},
+	{LanguageEnglish, LocationGhana, "en-GH"},
+	{LanguageEnglish, LocationIndia, "en-IN"},
+	{LanguageEnglish, LocationIndonesia, "en-ID"},
+	{LanguageEnglish, LocationIreland, "en-IE"},
+	{LanguageEnglish, LocationIsrael, "en-IL"},
+	{LanguageEnglish, LocationKenya, "en-KE"},
+	{LanguageEnglish, LocationLatvia, "en-LV"},
+	{LanguageEnglish, LocationMalaysia, "en-MY"},
+	{LanguageEnglish, LocationNamibia, "en-NA"},
+	{LanguageEnglish, LocationNewZealand, "en-NZ"},
+	{LanguageEnglish, LocationNigeria, "en-NG"},
+	{LanguageEnglish, LocationPakistan, "en-PK"},
+	{LanguageEnglish, LocationPhilippines, "en-PH"},
+	{LanguageEnglish, LocationSingapore, "en-SG"},
+	{LanguageEnglish, LocationSouthAfrica, "en-ZA"},
+	{LanguageEnglish, LocationTanzania, "en-TZ"},
+	{LanguageEnglish, LocationUganda, "en-UG"},
+	{LanguageEnglish, LocationUnitedKingdom, "en-GB"},
+	{LanguageEnglish, LocationZimbabwe, "en-ZW"},
+
+	// Europe
+	{LanguageCzech, LocationCzechRepublic, "cs"},
+	{LanguageGerman, LocationGermany, "de"},
+	{LanguageGerman, LocationAustria, "de"},
+	{LanguageGerman, LocationSwitzerland, "de"},
+	{LanguageSpanishSpain, LocationSpain, "es"},
+	{LanguageFrench, LocationFrance, "fr"},
+	{LanguageFrench, LocationBelgium, "fr"},
+	{LanguageFrench, LocationCanada, "fr-CA"},
+	{LanguageFrench, LocationMorocco, "fr"},
+	{LanguageFrench, LocationSenegal, "fr"},
+	{LanguageFrench, LocationSwitzerland, "fr"},
+	{LanguageItalian, LocationItaly, "it"},
+	{LanguageLatvian, LocationLatvia, "lv"},
+	{LanguageLithuanian, LocationLithuania, "lt"},
+	{LanguageHungarian, LocationHungary, "hu"},
+	{LanguageDutch, LocationNetherlands, "nl"},
+	{LanguageDutch, LocationBelgium, "nl"},
+	{LanguageNorwegian, LocationNorway, "no"},
+	{LanguagePolish, LocationPoland, "pl"},
+	{LanguagePortuguesePortugal, LocationPortugal, "pt-PT"},
+	{LanguageRomanian, LocationRomania, "ro"},
+	{LanguageSlovak, LocationSlovakia, "sk"},
+	{LanguageSlovenian, LocationSlovenia, "sl"},
+	{LanguageSwedish, LocationSweden, "sv"},
+	{LanguageGreek, LocationGreece, "el"},
+	{LanguageBulgarian, LocationBulgaria, "bg"},
+	{LanguageRussian, LocationRussia, "ru"},
+	{LanguageRussian, LocationUkraine, "ru"},
+	{LanguageSerbian, LocationSerbia, "sr"},
+	{LanguageUkrainian, LocationUkraine, "uk"},
+	{LanguageTurkish, LocationTurkey, "tr"},
+
+	// Americas
+	{LanguageSpanish, LocationMexico, "es-419"},
+	{LanguageSpanish, LocationArgentina, "es-419"},
+	{LanguageSpanish, LocationChile, "es-419"},
+	{LanguageSpanish, LocationColombia, "es-419"},
+	{LanguageSpanish, LocationCuba, "es-419"},
+	{LanguageSpanish, LocationPeru, "es-419"},
+	{LanguageSpanish, LocationUnitedStates, "es-419"},
+	{LanguageSpanish, LocationVenezuela, "es-419"},
+	{LanguagePortugueseBrasil, LocationBrazil, "pt-BR"},
+
+	// Middle East
+	{LanguageHebrew, LocationIsrael, "he"},
+	{LanguageArabic, LocationEgypt, "ar"},
+	{LanguageArabic, LocationLebanon, "ar"},
+	{LanguageArabic, LocationSaudiArabia, "ar"},
+	{LanguageArabic, LocationUnitedArabEmirates, "ar"},
+
+	// Asia
+	{LanguageIndonesian, LocationIndonesia, "id"},
+	{LanguageVietnamese, LocationVietnam, "vi"},
+	{LanguageMarathi, LocationIndia, "mr"},
+	{LanguageHindi, LocationIndia, "hi"},
+	{LanguageBengali, LocationBangladesh, "bn"},
+	{LanguageBengali, LocationIndia, "bn"},
+	{LanguageTamil, LocationIndia, "ta"},
+	{LanguageTelugu, LocationIndia, "te"},
+	{LanguageMalyalam, LocationIndia, "ml"},
+	{LanguageThai, LocationThailand, "th"},
+	{LanguageChineseSimplified, LocationChina, "zh-CN"},
+	{LanguageChineseTraditional, LocationTaiwan, "zh-TW"},
+	{LanguageChineseTraditional, LocationHongKong, "zh-HK"},
+	{LanguageJapanese, LocationJapan, "ja"},
+	{LanguageKorean, LocationRepublicOfKorea, "ko"},
+}
+
+var (
+	// editionIndex maps ceid to edition
+	editionIndex = map[string]Edition{}
+
+	// languageAliases maps deprecated or macro language subtags to the ones google news uses
+	languageAliases = map[string]string{
+		"iw": "he",
+		"in": "id",
+		"nb": "no",
+		"nn": "no",
+		"sh": "sr",
+	}
+)
+
+func init() {
+	for _, e := range Editions {
+		editionIndex[strings.ToLower(e.CEID())] = e
+	}
+}
+
+// LookupEdition looks up the edition of a language and location, e.g. LanguageChineseTraditional and LocationTaiwan
+func LookupEdition(language, location string) (Edition, error) {
+	e, ok := editionIndex[strings.ToLower(location+":"+language)]
+	if !ok {
+		return Edition{}, ErrUnsupportedEdition
+	}
+	return e, nil
+}
+
+// ParseEdition parses a BCP 47 language tag into the matching edition, e.g. "pt-BR" or "zh-Hant-HK".
+// Tags without a region resolve to the default edition of the language.
+func ParseEdition(tag string) (Edition, error) {
+	subtags := strings.FieldsFunc(tag, func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+	if len(subtags) == 0 {
+		return Edition{}, ErrEmptyEdition
+	}
+
+	base := strings.ToLower(subtags[0])
+	if alias, ok := languageAliases[base]; ok {
+		base = alias
+	}
+	var script, region string
+	for _, subtag := range subtags[1:] {
+		switch {
+		case len(subtag) == 4 && script == "" && region == "":
+			script = strings.ToUpper(subtag[:1]) + strings.ToLower(subtag[1:])
+		case (len(subtag) == 2 || len(subtag) == 3) && region == "":
+			region = strings.ToUpper(subtag)
+		}
+	}
+
+	var candidates []Edition
+	for _, e := range Editions {
+		lang, langScript, _ := splitLanguage(e.Language)
+		if lang != base {
+			continue
+		}
+		if script != "" && langScript != "" && langScript != script {
+			continue
+		}
+		candidates = append(candidates, e)
+	}
+	if len(candidates) == 0 {
+		return Edition{}, ErrUnsupportedEdition
+	}
+
+	if isCountryCode(region) {
+		// prefer the exact language of the region, e.g. "es-ES" over "es-419" in Spain
+		var found *Edition
+		for i, e := range candidates {
+			if e.Location != region {
+				continue
+			}
+			if found == nil || e.Language == base {
+				found = &candidates[i]
+			}
+		}
+		if found == nil {
+			return Edition{}, ErrUnsupportedEdition
+		}
+		return *found, nil
+	}
+	if region != "" {
+		// numeric region such as "419" selects the language variant
+		for _, e := range candidates {
+			if _, _, r := splitLanguage(e.Language); r == region {
+				return e, nil
+			}
+		}
+		return Edition{}, ErrUnsupportedEdition
+	}
+	return candidates[0], nil
+}
+
+// splitLanguage splits an edition language into base, script and region, e.g. "zh-Hant" or "pt-419"
+func splitLanguage(language string) (base, script, region string) {
+	parts := strings.Split(language, "-")
+	base = strings.ToLower(parts[0])
+	if len(parts) > 1 {
+		if len(parts[1]) == 4 {
+			script = parts[1]
+		} else {
+			region = strings.ToUpper(parts[1])
+		}
+	}
+	return base, script, region
+}
@@ -0,0 +1,102 @@
+package newsapi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Zhima-Mochi/newsApi-go/newsapi/newsapitest"
+)
+
+func TestParseEdition(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    string
+		wantErr error
+	}{
+		{"pt-BR", "BR:pt-419", nil},
+		{"pt", "PT:pt-150", nil},
+		{"zh-Hant-HK", "HK:zh-Hant", nil},
+		{"zh-TW", "TW:zh-Hant", nil},
+		{"zh_tw", "TW:zh-Hant", nil},
+		{"zh", "CN:zh-Hans", nil},
+		{"EN-us", "US:en", nil},
+		{"en-gb", "GB:en", nil},
+		// the language variant of a numeric region
+		{"es-419", "MX:es-419", nil},
+		{"es-ES", "ES:es", nil},
+		// a deprecated language subtag
+		{"iw", "IL:he", nil},
+		{"", "", ErrEmptyEdition},
+		{"xx", "", ErrUnsupportedEdition},
+		{"en-ZZ", "", ErrUnsupportedEdition},
+		{"zh-Hans-TW", "", ErrUnsupportedEdition},
+	}
+	for _, tt := range tests {
+		edition, err := ParseEdition(tt.tag)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("ParseEdition(%q): got error %v, want %v", tt.tag, err, tt.wantErr)
+			continue
+		}
+		if err == nil && edition.CEID() != tt.want {
+			t.Errorf("ParseEdition(%q): got %s, want %s", tt.tag, edition, tt.want)
+		}
+	}
+}
+
+func TestLookupEdition(t *testing.T) {
+	tests := []struct {
+		language, location string
+		hl                 string
+		wantErr            error
+	}{
+		{LanguageEnglish, LocationUnitedStates, "en-US", nil},
+		{"EN", "us", "en-US", nil},
+		{"zh-hant", "tw", "zh-TW", nil},
+		{LanguagePortugueseBrasil, LocationBrazil, "pt-BR", nil},
+		{LanguageEnglish, LocationTaiwan, "", ErrUnsupportedEdition},
+		{"", "", "", ErrUnsupportedEdition},
+	}
+	for _, tt := range tests {
+		edition, err := LookupEdition(tt.language, tt.location)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("LookupEdition(%q, %q): got error %v, want %v", tt.language, tt.location, err, tt.wantErr)
+			continue
+		}
+		if edition.HL != tt.hl {
+			t.Errorf("LookupEdition(%q, %q): got hl %q, want %q", tt.language, tt.location, edition.HL, tt.hl)
+		}
+	}
+}
+
+func TestEditionQuery(t *testing.T) {
+	server := newsapitest.NewServer()
+	defer server.Close()
+	server.SetTopNews(newsapitest.Fixture{Articles: testArticles(server)})
+	edition, err := ParseEdition("pt-BR")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := newTestNewsApi(server)
+	api.SetQueryOptions(WithEdition(edition))
+	newsList, err := api.GetTopNews()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newsList) == 0 || len(newsList[0].Editions) != 1 || newsList[0].Editions[0] != edition {
+		t.Errorf("got news %v, want the edition on every news", newsList)
+	}
+	requests := server.Requests()
+	if query := requests[len(requests)-1].Query; query.Get("hl") != "pt-BR" || query.Get("gl") != "BR" || query.Get("ceid") != "BR:pt-419" {
+		t.Errorf("got query %v", query)
+	}
+
+	// an unsupported pair fails before any request
+	api.SetQueryOptions(WithLanguage(LanguageEnglish), WithLocation(LocationTaiwan))
+	if _, err := api.GetTopNews(); !errors.Is(err, ErrUnsupportedEdition) {
+		t.Errorf("unsupported edition: got %v, want ErrUnsupportedEdition", err)
+	}
+	if len(server.Requests()) != len(requests) {
+		t.Error("an unsupported edition was requested")
+	}
+}
@@ -5,14 +5,72 @@ import "errors"
 var (
 	ErrEmptyQuery = errors.New("query cannot be empty")
 
+	ErrEmptyQueryName = errors.New("query name cannot be empty")
+
+	ErrDuplicateQueryName = errors.New("duplicate query name")
+
+	ErrInvalidQuery = errors.New("query must be made by a query function, e.g. NewTopQuery")
+
+	ErrWatcherStarted = errors.New("watcher already started")
+
+	ErrInvalidSeenKey = errors.New("seen key cannot contain a line break")
+
+	ErrEmptyRuleName = errors.New("rule name cannot be empty")
+
+	ErrDuplicateRuleName = errors.New("duplicate rule name")
+
+	ErrEmptyCondition = errors.New("rule condition cannot be empty")
+
+	ErrInvalidWindow = errors.New("rule with a threshold must have a positive window")
+
+	ErrEmptyNewsKey = errors.New("news must have a guid or a link")
+
+	ErrNewsNotFound = errors.New("news not found")
+
+	ErrUnknownColumn = errors.New("unknown column")
+
+	ErrNoRecipients = errors.New("no recipients")
+
+	ErrInvalidAddress = errors.New("invalid email address")
+
+	ErrInvalidTimeOfDay = errors.New("time of day must be within a day")
+
 	ErrEmptyTopic = errors.New("topic cannot be empty")
 
 	ErrInvalidTopic = errors.New("invalid topic")
 
 	ErrEmptyLocation = errors.New("location cannot be empty")
 
+	ErrInvalidPlace = errors.New("invalid place")
+
+	ErrEmptyEdition = errors.New("edition cannot be empty")
+
+	ErrUnsupportedEdition = errors.New("unsupported language and location combination")
+
+	ErrInvalidPeriod = errors.New("period must be positive")
+
+	ErrInvalidDateRange = errors.New("date range cannot be satisfied")
+
+	ErrNotSupported = errors.New("not supported by the provider")
+
+	ErrUnexpectedStatus = errors.New("unexpected response status")
+
 	ErrEmptyLink = errors.New("link cannot be empty")
 
+	ErrInvalidFeedURL = errors.New("feed url must be an absolute http or https url")
+
+	ErrInvalidProxy = errors.New("invalid proxy")
+
+	ErrNoHealthyProxy = errors.New("no healthy proxy")
+
+	ErrProxyBlocked = errors.New("proxy blocked")
+
+	ErrInvalidWARC = errors.New("invalid warc")
+
+	ErrNotArchived = errors.New("no archived response")
+
+	ErrNoInteraction = errors.New("no recorded interaction")
+
 	ErrNoSourceLink = errors.New("no source link")
 
 	ErrFailedToGetNewsContent = errors.New("failed to get news content")
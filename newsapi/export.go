@@ -0,0 +1,333 @@
+package newsapi
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultCSVListSeparator = "|"
+
+// DefaultCSVColumns are the csv columns written by default
+var DefaultCSVColumns = []string{
+	"title",
+	"link",
+	"source_link",
+	"published_parsed",
+	"source_site_name",
+	"description",
+	"categories",
+	"source_keywords",
+	"guid",
+	"topic",
+	"provider",
+}
+
+// csvColumn reads and writes a field of News as a csv cell
+type csvColumn struct {
+	get func(news *News, sep string) string
+	set func(news *News, value, sep string) error
+}
+
+func stringColumn(field func(news *News) *string) csvColumn {
+	return csvColumn{
+		get: func(news *News, _ string) string { return *field(news) },
+		set: func(news *News, value, _ string) error {
+			*field(news) = value
+			return nil
+		},
+	}
+}
+
+func intColumn(field func(news *News) *int) csvColumn {
+	return csvColumn{
+		get: func(news *News, _ string) string {
+			if *field(news) == 0 {
+				return ""
+			}
+			return strconv.Itoa(*field(news))
+		},
+		set: func(news *News, value, _ string) error {
+			if value == "" {
+				return nil
+			}
+			i, err := strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
+			*field(news) = i
+			return nil
+		},
+	}
+}
+
+func listColumn(field func(news *News) *[]string) csvColumn {
+	return csvColumn{
+		get: func(news *News, sep string) string { return strings.Join(*field(news), sep) },
+		set: func(news *News, value, sep string) error {
+			if value != "" {
+				*field(news) = strings.Split(value, sep)
+			}
+			return nil
+		},
+	}
+}
+
+func timeColumn(field func(news *News) **time.Time) csvColumn {
+	return csvColumn{
+		get: func(news *News, _ string) string {
+			if *field(news) == nil {
+				return ""
+			}
+			return (*field(news)).Format(time.RFC3339Nano)
+		},
+		set: func(news *News, value, _ string) error {
+			if value == "" {
+				return nil
+			}
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return err
+			}
+			*field(news) = &t
+			return nil
+		},
+	}
+}
+
+// csvColumns are the csv columns, named after the json names of the fields
+var csvColumns = map[string]csvColumn{
+	"title":               stringColumn(func(n *News) *string { return &n.Title }),
+	"description":         stringColumn(func(n *News) *string { return &n.Description }),
+	"link":                stringColumn(func(n *News) *string { return &n.Link }),
+	"links":               listColumn(func(n *News) *[]string { return &n.Links }),
+	"content":             stringColumn(func(n *News) *string { return &n.Content }),
+	"published":           stringColumn(func(n *News) *string { return &n.Published }),
+	"published_parsed":    timeColumn(func(n *News) **time.Time { return &n.PublishedParsed }),
+	"updated":             stringColumn(func(n *News) *string { return &n.Updated }),
+	"updated_parsed":      timeColumn(func(n *News) **time.Time { return &n.UpdatedParsed }),
+	"guid":                stringColumn(func(n *News) *string { return &n.GUID }),
+	"image_url":           stringColumn(func(n *News) *string { return &n.ImageURL }),
+	"categories":          listColumn(func(n *News) *[]string { return &n.Categories }),
+	"source_link":         stringColumn(func(n *News) *string { return &n.SourceLink }),
+	"source_title":        stringColumn(func(n *News) *string { return &n.SourceTitle }),
+	"source_image_url":    stringColumn(func(n *News) *string { return &n.SourceImageURL }),
+	"source_image_width":  intColumn(func(n *News) *int { return &n.SourceImageWidth }),
+	"source_image_height": intColumn(func(n *News) *int { return &n.SourceImageHeight }),
+	"source_description":  stringColumn(func(n *News) *string { return &n.SourceDescription }),
+	"source_keywords":     listColumn(func(n *News) *[]string { return &n.SourceKeywords }),
+	"source_site_name":    stringColumn(func(n *News) *string { return &n.SourceSiteName }),
+	"source_icon_url":     stringColumn(func(n *News) *string { return &n.SourceIconUrl }),
+	"source_content":      stringColumn(func(n *News) *string { return &n.SourceContent }),
+	"topic":               stringColumn(func(n *News) *string { return &n.Topic }),
+	"provider":            stringColumn(func(n *News) *string { return &n.Provider }),
+	"editions": {
+		get: func(news *News, sep string) string {
+			ceids := make([]string, 0, len(news.Editions))
+			for _, edition := range news.Editions {
+				ceids = append(ceids, edition.CEID())
+			}
+			return strings.Join(ceids, sep)
+		},
+		set: func(news *News, value, sep string) error {
+			if value == "" {
+				return nil
+			}
+			for _, ceid := range strings.Split(value, sep) {
+				edition, err := editionByCEID(ceid)
+				if err != nil {
+					return err
+				}
+				news.Editions = append(news.Editions, edition)
+			}
+			return nil
+		},
+	},
+	"queries": listColumn(func(n *News) *[]string { return &n.Queries }),
+}
+
+// editionByCEID returns the edition of a "Location:Language" ceid
+func editionByCEID(ceid string) (Edition, error) {
+	location, language, ok := strings.Cut(ceid, ":")
+	if !ok || location == "" || language == "" {
+		return Edition{}, fmt.Errorf("%w: %s", ErrUnsupportedEdition, ceid)
+	}
+	for _, edition := range Editions {
+		if edition.Location == location && edition.Language == language {
+			return edition, nil
+		}
+	}
+	return Edition{Language: language, Location: location}, nil
+}
+
+type csvConfig struct {
+	columns   []string
+	separator string
+}
+
+type CSVOption func(*csvConfig)
+
+// WithCSVColumns sets the columns written by WriteCSV, named after the json names of the News fields
+func WithCSVColumns(columns ...string) CSVOption {
+	return func(c *csvConfig) {
+		c.columns = columns
+	}
+}
+
+// WithCSVListSeparator sets the separator flattening lists like Categories and SourceKeywords into a cell, "|" by default
+func WithCSVListSeparator(separator string) CSVOption {
+	return func(c *csvConfig) {
+		if separator != "" {
+			c.separator = separator
+		}
+	}
+}
+
+func newCSVConfig(options []CSVOption) *csvConfig {
+	c := &csvConfig{
+		columns:   DefaultCSVColumns,
+		separator: defaultCSVListSeparator,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// WriteJSON writes the news as a json array
+func WriteJSON(w io.Writer, newsList []*News) error {
+	if newsList == nil {
+		newsList = []*News{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(newsList); err != nil {
+		return fmt.Errorf("error encoding news: %w", err)
+	}
+	return nil
+}
+
+// ReadJSON reads news written by WriteJSON
+func ReadJSON(r io.Reader) ([]*News, error) {
+	var newsList []*News
+	if err := json.NewDecoder(r).Decode(&newsList); err != nil {
+		return nil, fmt.Errorf("error decoding news: %w", err)
+	}
+	setFeedRanks(newsList)
+	return newsList, nil
+}
+
+// WriteNDJSON writes the news as newline delimited json, one news per line
+func WriteNDJSON(w io.Writer, newsList []*News) error {
+	encoder := json.NewEncoder(w)
+	for _, news := range newsList {
+		if err := encoder.Encode(news); err != nil {
+			return fmt.Errorf("error encoding news: %w", err)
+		}
+	}
+	return nil
+}
+
+// ReadNDJSON reads news written by WriteNDJSON
+func ReadNDJSON(r io.Reader) ([]*News, error) {
+	var newsList []*News
+	decoder := json.NewDecoder(r)
+	for {
+		news := &News{}
+		err := decoder.Decode(news)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error decoding news %d: %w", len(newsList)+1, err)
+		}
+		newsList = append(newsList, news)
+	}
+	setFeedRanks(newsList)
+	return newsList, nil
+}
+
+// WriteCSV writes the news as csv with a header row, lists are joined with the list separator
+func WriteCSV(w io.Writer, newsList []*News, options ...CSVOption) error {
+	c := newCSVConfig(options)
+	columns := make([]csvColumn, len(c.columns))
+	for i, name := range c.columns {
+		column, ok := csvColumns[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownColumn, name)
+		}
+		columns[i] = column
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(c.columns); err != nil {
+		return fmt.Errorf("error writing csv: %w", err)
+	}
+	record := make([]string, len(columns))
+	for _, news := range newsList {
+		for i, column := range columns {
+			record[i] = column.get(news, c.separator)
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error writing csv: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error writing csv: %w", err)
+	}
+	return nil
+}
+
+// ReadCSV reads news written by WriteCSV, the columns are read from the header row.
+// Only WithCSVListSeparator is used, it must match the one used to write.
+func ReadCSV(r io.Reader, options ...CSVOption) ([]*News, error) {
+	c := newCSVConfig(options)
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading csv header: %w", err)
+	}
+	columns := make([]csvColumn, len(header))
+	for i, name := range header {
+		column, ok := csvColumns[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownColumn, name)
+		}
+		columns[i] = column
+	}
+
+	var newsList []*News
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading csv: %w", err)
+		}
+		news := &News{}
+		for i, column := range columns {
+			if err := column.set(news, record[i], c.separator); err != nil {
+				return nil, fmt.Errorf("error reading csv line %d column %s: %w", len(newsList)+2, header[i], err)
+			}
+		}
+		newsList = append(newsList, news)
+	}
+	setFeedRanks(newsList)
+	return newsList, nil
+}
+
+// setFeedRanks keeps the order of imported news for SortByFeedOrder
+func setFeedRanks(newsList []*News) {
+	for i, news := range newsList {
+		if news != nil {
+			news.feedRank = i
+		}
+	}
+}
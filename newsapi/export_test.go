@@ -0,0 +1,102 @@
+package newsapi
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exportTestNews() []*News {
+	published := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	return []*News{
+		{
+			Title:           "Chip exports rise, again",
+			Link:            "https://news.google.com/rss/articles/CBMi1",
+			SourceLink:      "https://www.reuters.com/chips",
+			PublishedParsed: &published,
+			SourceSiteName:  "Reuters",
+			Description:     "Exports \"rose\"\nagain",
+			Categories:      []string{"Business", "Technology"},
+			SourceKeywords:  []string{"chips"},
+			GUID:            "CBMi1",
+			Topic:           TopicBusiness,
+			Provider:        ProviderGoogle,
+			Editions:        []Edition{{Language: "en", Location: "US", HL: "en-US"}},
+		},
+		{Title: "Markets close higher", Link: "https://apnews.com/markets", Provider: ProviderFeed},
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	newsList := exportTestNews()
+	for name, codec := range map[string]struct {
+		write func(*bytes.Buffer, []*News) error
+		read  func(*bytes.Buffer) ([]*News, error)
+	}{
+		"json": {
+			func(b *bytes.Buffer, l []*News) error { return WriteJSON(b, l) },
+			func(b *bytes.Buffer) ([]*News, error) { return ReadJSON(b) },
+		},
+		"ndjson": {
+			func(b *bytes.Buffer, l []*News) error { return WriteNDJSON(b, l) },
+			func(b *bytes.Buffer) ([]*News, error) { return ReadNDJSON(b) },
+		},
+	} {
+		var buf bytes.Buffer
+		if err := codec.write(&buf, newsList); err != nil {
+			t.Fatalf("%s: writing: %v", name, err)
+		}
+		read, err := codec.read(&buf)
+		if err != nil {
+			t.Fatalf("%s: reading: %v", name, err)
+		}
+		if len(read) != len(newsList) {
+			t.Fatalf("%s: got %d news, want %d", name, len(read), len(newsList))
+		}
+		for i := range read {
+			read[i].feedRank = 0
+			if !reflect.DeepEqual(read[i], newsList[i]) {
+				t.Errorf("%s: news %d: got %+v, want %+v", name, i, read[i], newsList[i])
+			}
+		}
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	newsList := exportTestNews()
+	var buf bytes.Buffer
+	columns := append(append([]string(nil), DefaultCSVColumns...), "editions")
+	if err := WriteCSV(&buf, newsList, WithCSVColumns(columns...), WithCSVListSeparator(";")); err != nil {
+		t.Fatal(err)
+	}
+	if header := strings.SplitN(buf.String(), "\n", 2)[0]; header != strings.Join(columns, ",") {
+		t.Errorf("header: got %q", header)
+	}
+	read, err := ReadCSV(&buf, WithCSVListSeparator(";"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 2 {
+		t.Fatalf("got %d news, want 2", len(read))
+	}
+	news := read[0]
+	if news.Title != newsList[0].Title || news.Description != newsList[0].Description ||
+		!news.PublishedParsed.Equal(*newsList[0].PublishedParsed) ||
+		!reflect.DeepEqual(news.Categories, newsList[0].Categories) ||
+		len(news.Editions) != 1 || news.Editions[0].CEID() != "US:en" {
+		t.Errorf("got %+v", news)
+	}
+	if read[1].PublishedParsed != nil || read[1].Categories != nil {
+		t.Errorf("empty cells: got %+v", read[1])
+	}
+
+	if err := WriteCSV(&buf, newsList, WithCSVColumns("title", "nope")); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("unknown column: got %v, want ErrUnknownColumn", err)
+	}
+	if _, err := ReadCSV(strings.NewReader("title,nope\n")); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("unknown column: got %v, want ErrUnknownColumn", err)
+	}
+}
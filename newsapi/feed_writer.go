@@ -0,0 +1,360 @@
+package newsapi
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"time"
+)
+
+const (
+	defaultFeedTitle = "News"
+	defaultFeedLink  = "https://news.google.com"
+
+	jsonFeedVersion = "https://jsonfeed.org/version/1.1"
+	atomNamespace   = "http://www.w3.org/2005/Atom"
+)
+
+// FeedInfo describes a feed written by WriteRSS, WriteAtom and WriteJSONFeed
+type FeedInfo struct {
+	// Title is the title of the feed, "News" by default
+	Title string
+	// Link is the url of the website of the feed, google news by default
+	Link string
+	// FeedURL is the url the feed is served at
+	FeedURL     string
+	Description string
+	// Language is the language of the feed, e.g. LanguageEnglish
+	Language string
+	// Author is the author of the feed, the title by default
+	Author string
+	// Updated is the last update of the feed, the latest news date by default
+	Updated time.Time
+}
+
+func (info FeedInfo) withDefaults(newsList []*News) FeedInfo {
+	if info.Title == "" {
+		info.Title = defaultFeedTitle
+	}
+	if info.Link == "" {
+		info.Link = defaultFeedLink
+	}
+	if info.Author == "" {
+		info.Author = info.Title
+	}
+	if info.Updated.IsZero() {
+		for _, news := range newsList {
+			if t := news.updatedOrPublished(); t != nil && t.After(info.Updated) {
+				info.Updated = *t
+			}
+		}
+	}
+	if info.Updated.IsZero() {
+		info.Updated = time.Now()
+	}
+	return info
+}
+
+// feedItemID returns the id of the news in a feed, its guid or its link
+func feedItemID(news *News) string {
+	if news.GUID != "" {
+		return news.GUID
+	}
+	return notificationLink(news)
+}
+
+// feedItemSummary returns the source description of the news, or its feed description
+func feedItemSummary(news *News) string {
+	if news.SourceDescription != "" {
+		return news.SourceDescription
+	}
+	return news.Description
+}
+
+// imageType returns the media type of an image url from its extension, image/jpeg by default
+func imageType(imageURL string) string {
+	u, err := url.Parse(imageURL)
+	if err == nil {
+		if t := mime.TypeByExtension(path.Ext(u.Path)); t != "" {
+			return t
+		}
+	}
+	return "image/jpeg"
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	Self          *atomLink  `xml:"atom:link,omitempty"`
+	Language      string     `xml:"language,omitempty"`
+	LastBuildDate string     `xml:"lastBuildDate"`
+	Generator     string     `xml:"generator"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	Description string        `xml:"description,omitempty"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate,omitempty"`
+	Source      *rssSource    `xml:"source,omitempty"`
+	Categories  []string      `xml:"category"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type rssSource struct {
+	Name string `xml:",chardata"`
+	URL  string `xml:"url,attr"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int    `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// WriteRSS writes the news as a rss 2.0 feed, linking to their source links
+func WriteRSS(w io.Writer, info FeedInfo, newsList []*News) error {
+	info = info.withDefaults(newsList)
+	channel := rssChannel{
+		Title:         info.Title,
+		Link:          info.Link,
+		Description:   info.Description,
+		Language:      info.Language,
+		LastBuildDate: info.Updated.Format(time.RFC1123Z),
+		Generator:     "newsApi-go",
+		Items:         make([]*rssItem, 0, len(newsList)),
+	}
+	if channel.Description == "" {
+		channel.Description = info.Title
+	}
+	if info.FeedURL != "" {
+		channel.Self = &atomLink{Href: info.FeedURL, Rel: "self", Type: "application/rss+xml"}
+	}
+	for _, news := range newsList {
+		item := &rssItem{
+			Title:       dedupTitle(news),
+			Link:        notificationLink(news),
+			Description: feedItemSummary(news),
+			GUID:        rssGUID{Value: feedItemID(news), IsPermaLink: news.GUID == ""},
+			Categories:  news.Categories,
+		}
+		if news.PublishedParsed != nil {
+			item.PubDate = news.PublishedParsed.Format(time.RFC1123Z)
+		}
+		if publisher := news.Publisher(); publisher != "" && news.SourceLink != "" {
+			if u, err := url.Parse(news.SourceLink); err == nil {
+				item.Source = &rssSource{Name: publisher, URL: u.Scheme + "://" + u.Host}
+			}
+		}
+		if image := notificationImage(news); image != "" {
+			item.Enclosure = &rssEnclosure{URL: image, Type: imageType(image)}
+		}
+		channel.Items = append(channel.Items, item)
+	}
+	return writeXML(w, rssDocument{Version: "2.0", Atom: atomNamespace, Channel: channel})
+}
+
+type atomFeed struct {
+	XMLName  xml.Name     `xml:"feed"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Lang     string       `xml:"xml:lang,attr,omitempty"`
+	ID       string       `xml:"id"`
+	Title    string       `xml:"title"`
+	Subtitle string       `xml:"subtitle,omitempty"`
+	Updated  string       `xml:"updated"`
+	Author   atomPerson   `xml:"author"`
+	Links    []atomLink   `xml:"link"`
+	Entries  []*atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Summary    string         `xml:"summary,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+// WriteAtom writes the news as an atom 1.0 feed, linking to their source links
+func WriteAtom(w io.Writer, info FeedInfo, newsList []*News) error {
+	info = info.withDefaults(newsList)
+	feed := atomFeed{
+		Xmlns:    atomNamespace,
+		Lang:     info.Language,
+		ID:       info.FeedURL,
+		Title:    info.Title,
+		Subtitle: info.Description,
+		Updated:  info.Updated.Format(time.RFC3339),
+		Author:   atomPerson{Name: info.Author},
+		Links:    []atomLink{{Href: info.Link, Rel: "alternate"}},
+		Entries:  make([]*atomEntry, 0, len(newsList)),
+	}
+	if feed.ID == "" {
+		feed.ID = info.Link
+	} else {
+		feed.Links = append(feed.Links, atomLink{Href: info.FeedURL, Rel: "self", Type: "application/atom+xml"})
+	}
+	for _, news := range newsList {
+		link := notificationLink(news)
+		entry := &atomEntry{
+			ID:      atomEntryID(news),
+			Title:   dedupTitle(news),
+			Updated: info.Updated.Format(time.RFC3339),
+			Links:   []atomLink{{Href: link, Rel: "alternate", Type: "text/html"}},
+			Summary: feedItemSummary(news),
+		}
+		if t := news.updatedOrPublished(); t != nil {
+			entry.Updated = t.Format(time.RFC3339)
+		}
+		if news.PublishedParsed != nil {
+			entry.Published = news.PublishedParsed.Format(time.RFC3339)
+		}
+		if publisher := news.Publisher(); publisher != "" {
+			entry.Author = &atomPerson{Name: publisher}
+		}
+		if image := notificationImage(news); image != "" {
+			entry.Links = append(entry.Links, atomLink{Href: image, Rel: "enclosure", Type: imageType(image)})
+		}
+		for _, category := range news.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return writeXML(w, feed)
+}
+
+// atomEntryID returns the id of the news in an atom feed, which must be an absolute iri
+func atomEntryID(news *News) string {
+	if u, err := url.Parse(news.GUID); err == nil && u.IsAbs() {
+		return news.GUID
+	}
+	if news.GUID != "" {
+		return "urn:newsapi:" + url.PathEscape(news.GUID)
+	}
+	return notificationLink(news)
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("error writing feed: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("error writing feed: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("error writing feed: %w", err)
+	}
+	return nil
+}
+
+type jsonFeed struct {
+	Version     string          `json:"version"`
+	Title       string          `json:"title"`
+	HomePageURL string          `json:"home_page_url,omitempty"`
+	FeedURL     string          `json:"feed_url,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Language    string          `json:"language,omitempty"`
+	Authors     []jsonFeedActor `json:"authors,omitempty"`
+	Items       []*jsonFeedItem `json:"items"`
+}
+
+type jsonFeedActor struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type jsonFeedItem struct {
+	ID            string          `json:"id"`
+	URL           string          `json:"url,omitempty"`
+	Title         string          `json:"title,omitempty"`
+	ContentText   string          `json:"content_text"`
+	Summary       string          `json:"summary,omitempty"`
+	Image         string          `json:"image,omitempty"`
+	DatePublished string          `json:"date_published,omitempty"`
+	DateModified  string          `json:"date_modified,omitempty"`
+	Authors       []jsonFeedActor `json:"authors,omitempty"`
+	Tags          []string        `json:"tags,omitempty"`
+}
+
+// WriteJSONFeed writes the news as a json feed 1.1, linking to their source links
+func WriteJSONFeed(w io.Writer, info FeedInfo, newsList []*News) error {
+	info = info.withDefaults(newsList)
+	feed := jsonFeed{
+		Version:     jsonFeedVersion,
+		Title:       info.Title,
+		HomePageURL: info.Link,
+		FeedURL:     info.FeedURL,
+		Description: info.Description,
+		Language:    info.Language,
+		Authors:     []jsonFeedActor{{Name: info.Author}},
+		Items:       make([]*jsonFeedItem, 0, len(newsList)),
+	}
+	for _, news := range newsList {
+		item := &jsonFeedItem{
+			ID:          feedItemID(news),
+			URL:         notificationLink(news),
+			Title:       dedupTitle(news),
+			ContentText: feedItemSummary(news),
+			Image:       notificationImage(news),
+			Tags:        news.Categories,
+		}
+		if item.ContentText == "" {
+			item.ContentText = item.Title
+		}
+		if news.PublishedParsed != nil {
+			item.DatePublished = news.PublishedParsed.Format(time.RFC3339)
+		}
+		if news.UpdatedParsed != nil {
+			item.DateModified = news.UpdatedParsed.Format(time.RFC3339)
+		}
+		if publisher := news.Publisher(); publisher != "" {
+			item.Authors = []jsonFeedActor{{Name: publisher}}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(feed); err != nil {
+		return fmt.Errorf("error writing feed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package newsapi
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestFeedWriters(t *testing.T) {
+	newsList := exportTestNews()
+	info := FeedInfo{
+		Title:    "Business news",
+		Link:     "https://example.com",
+		FeedURL:  "https://example.com/feed",
+		Language: LanguageEnglish,
+	}
+	for name, write := range map[string]func(*bytes.Buffer) error{
+		"rss":  func(b *bytes.Buffer) error { return WriteRSS(b, info, newsList) },
+		"atom": func(b *bytes.Buffer) error { return WriteAtom(b, info, newsList) },
+		"json": func(b *bytes.Buffer) error { return WriteJSONFeed(b, info, newsList) },
+	} {
+		var buf bytes.Buffer
+		if err := write(&buf); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		feed, err := gofeed.NewParser().Parse(&buf)
+		if err != nil {
+			t.Fatalf("%s: parsing: %v", name, err)
+		}
+		if feed.Title != info.Title {
+			t.Errorf("%s: got title %q", name, feed.Title)
+		}
+		if len(feed.Items) != len(newsList) {
+			t.Fatalf("%s: got %d items, want %d", name, len(feed.Items), len(newsList))
+		}
+		item := feed.Items[0]
+		if item.Title != newsList[0].Title || item.Link != newsList[0].SourceLink {
+			t.Errorf("%s: got item %q %q", name, item.Title, item.Link)
+		}
+		if item.PublishedParsed == nil || !item.PublishedParsed.Equal(*newsList[0].PublishedParsed) {
+			t.Errorf("%s: got published %v", name, item.PublishedParsed)
+		}
+
+		// the written feed is read back as news
+		read := NewNews(item)
+		if read.Title != newsList[0].Title {
+			t.Errorf("%s: got news %+v", name, read)
+		}
+	}
+}
+
+func TestFeedInfoDefaults(t *testing.T) {
+	published := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	info := FeedInfo{}.withDefaults([]*News{{PublishedParsed: &published}, {}})
+	if info.Title != defaultFeedTitle || info.Link != defaultFeedLink || info.Author != defaultFeedTitle || !info.Updated.Equal(published) {
+		t.Errorf("got %+v", info)
+	}
+}
@@ -0,0 +1,229 @@
+package newsapi
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// HeaderProfile is a coherent set of browser headers, sent together so that requests look like one browser
+type HeaderProfile struct {
+	Name      string
+	UserAgent string
+	Accept    string
+	// SecCHUA is the Sec-CH-UA client hint, only sent by chromium browsers
+	SecCHUA         string
+	SecCHUAMobile   string
+	SecCHUAPlatform string
+}
+
+const (
+	chromiumAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7"
+	browserAccept  = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+)
+
+var (
+	ProfileChromeWindows = HeaderProfile{
+		Name:            "chrome-windows",
+		UserAgent:       "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36",
+		Accept:          chromiumAccept,
+		SecCHUA:         `"Google Chrome";v="131", "Chromium";v="131", "Not_A Brand";v="24"`,
+		SecCHUAMobile:   "?0",
+		SecCHUAPlatform: `"Windows"`,
+	}
+
+	ProfileChromeMacOS = HeaderProfile{
+		Name:            "chrome-macos",
+		UserAgent:       "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36",
+		Accept:          chromiumAccept,
+		SecCHUA:         `"Google Chrome";v="131", "Chromium";v="131", "Not_A Brand";v="24"`,
+		SecCHUAMobile:   "?0",
+		SecCHUAPlatform: `"macOS"`,
+	}
+
+	ProfileEdgeWindows = HeaderProfile{
+		Name:            "edge-windows",
+		UserAgent:       "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36 Edg/131.0.0.0",
+		Accept:          chromiumAccept,
+		SecCHUA:         `"Microsoft Edge";v="131", "Chromium";v="131", "Not_A Brand";v="24"`,
+		SecCHUAMobile:   "?0",
+		SecCHUAPlatform: `"Windows"`,
+	}
+
+	ProfileFirefoxWindows = HeaderProfile{
+		Name:      "firefox-windows",
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:133.0) Gecko/20100101 Firefox/133.0",
+		Accept:    browserAccept,
+	}
+
+	ProfileFirefoxLinux = HeaderProfile{
+		Name:      "firefox-linux",
+		UserAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:133.0) Gecko/20100101 Firefox/133.0",
+		Accept:    browserAccept,
+	}
+
+	ProfileSafariMacOS = HeaderProfile{
+		Name:      "safari-macos",
+		UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.1 Safari/605.1.15",
+		Accept:    browserAccept,
+	}
+
+	// DefaultHeaderProfiles are the profiles used by default, of current desktop browsers
+	DefaultHeaderProfiles = []HeaderProfile{
+		ProfileChromeWindows,
+		ProfileChromeMacOS,
+		ProfileEdgeWindows,
+		ProfileFirefoxWindows,
+		ProfileFirefoxLinux,
+		ProfileSafariMacOS,
+	}
+)
+
+// ProfileRotation is when Headers switch to another profile
+type ProfileRotation int
+
+const (
+	// RotatePerClient keeps one random profile for every request
+	RotatePerClient ProfileRotation = iota
+	// RotatePerHost keeps one random profile for every request to a host
+	RotatePerHost
+	// RotatePerRequest picks a random profile for every request
+	RotatePerRequest
+)
+
+// Headers sets the headers of a header profile on the requests
+type Headers struct {
+	mu       sync.Mutex
+	profiles []HeaderProfile
+	rotation ProfileRotation
+	current  int
+	byHost   map[string]int
+}
+
+type HeadersOption func(*Headers)
+
+// WithHeaderProfiles sets the profiles to pick from, DefaultHeaderProfiles by default
+func WithHeaderProfiles(profiles ...HeaderProfile) HeadersOption {
+	return func(h *Headers) {
+		if len(profiles) > 0 {
+			h.profiles = profiles
+		}
+	}
+}
+
+// WithProfileRotation sets when another profile is picked, RotatePerClient by default
+func WithProfileRotation(rotation ProfileRotation) HeadersOption {
+	return func(h *Headers) {
+		h.rotation = rotation
+	}
+}
+
+// NewHeaders returns headers picking from the profiles
+func NewHeaders(options ...HeadersOption) *Headers {
+	h := &Headers{
+		profiles: DefaultHeaderProfiles,
+		rotation: RotatePerClient,
+		byHost:   map[string]int{},
+	}
+	for _, option := range options {
+		option(h)
+	}
+	h.current = rand.Intn(len(h.profiles))
+	return h
+}
+
+// Profile returns the profile of a request to the host
+func (h *Headers) Profile(host string) HeaderProfile {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch h.rotation {
+	case RotatePerRequest:
+		return h.profiles[rand.Intn(len(h.profiles))]
+	case RotatePerHost:
+		i, ok := h.byHost[host]
+		if !ok {
+			i = rand.Intn(len(h.profiles))
+			h.byHost[host] = i
+		}
+		return h.profiles[i]
+	default:
+		return h.profiles[h.current]
+	}
+}
+
+// Apply sets the headers of the profile of the request, with the Accept-Language of the language tag, e.g. "pt-BR".
+// The Accept and Accept-Language headers already set are kept, except the "*/*" Accept of generic clients.
+func (h *Headers) Apply(req *http.Request, language string) {
+	profile := h.Profile(req.URL.Hostname())
+	req.Header.Set("User-Agent", profile.UserAgent)
+	if accept := req.Header.Get("Accept"); accept == "" || accept == "*/*" {
+		req.Header.Set("Accept", profile.Accept)
+	}
+	if req.Header.Get("Accept-Language") == "" {
+		req.Header.Set("Accept-Language", AcceptLanguage(language))
+	}
+	if profile.SecCHUA != "" {
+		req.Header.Set("Sec-CH-UA", profile.SecCHUA)
+		req.Header.Set("Sec-CH-UA-Mobile", profile.SecCHUAMobile)
+		req.Header.Set("Sec-CH-UA-Platform", profile.SecCHUAPlatform)
+	} else {
+		req.Header.Del("Sec-CH-UA")
+		req.Header.Del("Sec-CH-UA-Mobile")
+		req.Header.Del("Sec-CH-UA-Platform")
+	}
+	req.Header.Set("Upgrade-Insecure-Requests", "1")
+}
+
+// AcceptLanguage returns the Accept-Language header of a language tag, falling back to english as browsers do,
+// e.g. "pt-BR,pt;q=0.9,en-US;q=0.8,en;q=0.7"
+func AcceptLanguage(language string) string {
+	if language == "" {
+		language = "en-US"
+	}
+	tags := []string{language}
+	base, _, regional := strings.Cut(language, "-")
+	if regional {
+		tags = append(tags, base)
+	}
+	if base != "en" {
+		tags = append(tags, "en-US", "en")
+	} else if !regional {
+		tags = []string{"en-US", "en"}
+	}
+	values := make([]string, len(tags))
+	for i, tag := range tags {
+		if i == 0 {
+			values[i] = tag
+			continue
+		}
+		values[i] = fmt.Sprintf("%s;q=0.%d", tag, 10-i)
+	}
+	return strings.Join(values, ",")
+}
+
+// headersTransport sets the headers on a copy of every request
+type headersTransport struct {
+	transport http.RoundTripper
+	headers   *Headers
+	language  string
+}
+
+// NewHeadersTransport returns a transport setting the headers on every request of transport, http.DefaultTransport if nil.
+// The headers are new ones if nil. The Accept-Language header is derived from the language tag, e.g. "pt-BR".
+func NewHeadersTransport(transport http.RoundTripper, headers *Headers, language string) http.RoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if headers == nil {
+		headers = NewHeaders()
+	}
+	return &headersTransport{transport: transport, headers: headers, language: language}
+}
+
+func (t *headersTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	t.headers.Apply(req, t.language)
+	return t.transport.RoundTrip(req)
+}
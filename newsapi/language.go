@@ -6,6 +6,7 @@ const (
 	LanguageCzech              = "cs"
 	LanguageGerman             = "de"
 	LanguageSpanish            = "es-419"
+	LanguageSpanishSpain       = "es"
 	LanguageFrench             = "fr"
 	LanguageItalian            = "it"
 	LanguageLatvian            = "lv"
@@ -7,8 +7,10 @@ const (
 	LocationEthiopia    = "ET"
 	LocationGhana       = "GH"
 	LocationKenya       = "KE"
+	LocationMorocco     = "MA"
 	LocationNamibia     = "NA"
 	LocationNigeria     = "NG"
+	LocationSenegal     = "SN"
 	LocationSouthAfrica = "ZA"
 	LocationTanzania    = "TZ"
 	LocationUganda      = "UG"
@@ -53,6 +55,7 @@ const (
 	LocationRomania       = "RO"
 	LocationSlovakia      = "SK"
 	LocationSlovenia      = "SI"
+	LocationSpain         = "ES"
 	LocationSweden        = "SE"
 	LocationGreece        = "GR"
 	LocationBulgaria      = "BG"
@@ -67,12 +70,16 @@ const (
 
 	// South America
 	LocationArgentina = "AR"
+	LocationBrazil    = "BR"
 	LocationChile     = "CL"
 	LocationColombia  = "CO"
 	LocationCuba      = "CU"
 	LocationPeru      = "PE"
 	LocationVenezuela = "VE"
 
+	// Oceania
+	LocationNewZealand = "NZ"
+
 	// Middle East
 	LocationUnitedArabEmirates = "AE"
 	LocationSaudiArabia        = "SA"
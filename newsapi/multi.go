@@ -0,0 +1,248 @@
+package newsapi
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultEditionConcurrency = 4
+	defaultEditionInterval    = 200 * time.Millisecond
+)
+
+// EditionError is the error of a single edition in a multi-edition query
+type EditionError struct {
+	Edition Edition
+	Err     error
+}
+
+func (e *EditionError) Error() string {
+	return fmt.Sprintf("edition %s: %s", e.Edition, e.Err)
+}
+
+func (e *EditionError) Unwrap() error {
+	return e.Err
+}
+
+// MultiEditionError collects the errors of the failed editions in a multi-edition query
+type MultiEditionError []*EditionError
+
+func (e MultiEditionError) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d edition(s) failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+type multiEditionQuery struct {
+	concurrency        int
+	interval           time.Duration
+	resolveSourceLinks bool
+}
+
+// MultiEditionOption configures a multi-edition query
+type MultiEditionOption func(*multiEditionQuery)
+
+// WithMaxConcurrency sets how many editions are queried at the same time
+func WithMaxConcurrency(concurrency int) MultiEditionOption {
+	return func(q *multiEditionQuery) {
+		if concurrency > 0 {
+			q.concurrency = concurrency
+		}
+	}
+}
+
+// WithRequestInterval sets the minimal interval between two requests to google news
+func WithRequestInterval(interval time.Duration) MultiEditionOption {
+	return func(q *multiEditionQuery) {
+		q.interval = interval
+	}
+}
+
+// WithoutSourceLinkResolution de-duplicates by the google news link instead of the resolved source link
+func WithoutSourceLinkResolution() MultiEditionOption {
+	return func(q *multiEditionQuery) {
+		q.resolveSourceLinks = false
+	}
+}
+
+// SearchNewsInEditions searches the news by query in every edition and merges the results.
+// When only some editions fail, the merged news is returned along with a MultiEditionError.
+func (n *newsApi) SearchNewsInEditions(query string, editions []Edition, options ...MultiEditionOption) ([]*News, error) {
+	if query == "" {
+		return nil, ErrEmptyQuery
+	}
+	return n.queryEditions(editions, options, func(api *newsApi) ([]*News, error) {
+		return api.SearchNews(query)
+	})
+}
+
+// GetTopicNewsInEditions gets the news by topic in every edition and merges the results.
+// When only some editions fail, the merged news is returned along with a MultiEditionError.
+func (n *newsApi) GetTopicNewsInEditions(topic string, editions []Edition, options ...MultiEditionOption) ([]*News, error) {
+	if topic == "" {
+		return nil, ErrEmptyTopic
+	}
+	return n.queryEditions(editions, options, func(api *newsApi) ([]*News, error) {
+		return api.GetTopicNews(topic)
+	})
+}
+
+// queryEditions runs fetch with a copy of n for every edition, then merges the results
+func (n *newsApi) queryEditions(editions []Edition, options []MultiEditionOption, fetch func(api *newsApi) ([]*News, error)) ([]*News, error) {
+	if len(editions) == 0 {
+		return nil, ErrEmptyEdition
+	}
+	q := &multiEditionQuery{
+		concurrency:        defaultEditionConcurrency,
+		interval:           defaultEditionInterval,
+		resolveSourceLinks: true,
+	}
+	for _, option := range options {
+		option(q)
+	}
+
+	var throttle <-chan time.Time
+	if q.interval > 0 {
+		ticker := time.NewTicker(q.interval)
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	results := make([][]*News, len(editions))
+	errs := make([]error, len(editions))
+	sem := make(chan struct{}, q.concurrency)
+	var wg sync.WaitGroup
+	for i, edition := range editions {
+		wg.Add(1)
+		go func(i int, edition Edition) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if throttle != nil {
+				<-throttle
+			}
+
+			api := *n
+			WithEdition(edition)(&api)
+			results[i], errs[i] = fetch(&api)
+		}(i, edition)
+	}
+	wg.Wait()
+
+	var multiErr MultiEditionError
+	var newsList []*News
+	for i, edition := range editions {
+		if errs[i] != nil {
+			multiErr = append(multiErr, &EditionError{Edition: edition, Err: errs[i]})
+			continue
+		}
+		newsList = append(newsList, results[i]...)
+	}
+	if len(multiErr) == len(editions) {
+		return nil, multiErr
+	}
+
+	if q.resolveSourceLinks {
+		n.FetchSourceLinks(newsList, WithFetchConcurrency(q.concurrency), WithFetchInterval(q.interval))
+	}
+	newsList = MergeNews(newsList)
+	SortNews(newsList, n.sortOrder)
+
+	if len(multiErr) > 0 {
+		return newsList, multiErr
+	}
+	return newsList, nil
+}
+
+// MergeNews merges duplicated news by their GUID or canonical source link, keeping the first one
+// and collecting the editions and queries of the others. The order of first appearance is kept.
+func MergeNews(newsList []*News) []*News {
+	merged := make([]*News, 0, len(newsList))
+	seenKeys := make(map[string]*News, len(newsList))
+	seenGUIDs := make(map[string]*News, len(newsList))
+	for _, news := range newsList {
+		key := news.dedupKey()
+		first, ok := seenKeys[key]
+		if !ok && news.GUID != "" {
+			first, ok = seenGUIDs[news.GUID]
+		}
+		if ok {
+			first.Editions = appendEditions(first.Editions, news.Editions...)
+			first.Queries = appendStrings(first.Queries, news.Queries...)
+			// remember the keys of the duplicate, which may differ from the first one
+			seenKeys[key] = first
+			if news.GUID != "" {
+				seenGUIDs[news.GUID] = first
+			}
+			continue
+		}
+		seenKeys[key] = news
+		if news.GUID != "" {
+			seenGUIDs[news.GUID] = news
+		}
+		merged = append(merged, news)
+	}
+	return merged
+}
+
+// appendEditions appends the editions which are not in the list yet
+func appendEditions(list []Edition, editions ...Edition) []Edition {
+	for _, edition := range editions {
+		found := false
+		for _, e := range list {
+			if e == edition {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, edition)
+		}
+	}
+	return list
+}
+
+// appendStrings appends the strings which are not in the list yet
+func appendStrings(list []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, v := range list {
+			if v == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+// CanonicalURL normalizes a link for comparison.
+// The scheme, "www." prefix, fragment, trailing slash and tracking parameters are ignored.
+func CanonicalURL(link string) string {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.Host == "" {
+		return link
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	path := strings.TrimSuffix(u.EscapedPath(), "/")
+
+	q := u.Query()
+	for key := range q {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") {
+			q.Del(key)
+		}
+	}
+	canonical := host + path
+	if len(q) > 0 {
+		canonical += "?" + q.Encode()
+	}
+	return canonical
+}
@@ -0,0 +1,127 @@
+package newsapi
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Zhima-Mochi/newsApi-go/newsapi/newsapitest"
+)
+
+func multiTestEditions(t *testing.T) []Edition {
+	t.Helper()
+	var editions []Edition
+	for _, pair := range [][2]string{
+		{LanguageEnglish, LocationUnitedStates},
+		{LanguageEnglish, LocationUnitedKingdom},
+		{LanguageChineseTraditional, LocationTaiwan},
+	} {
+		edition, err := LookupEdition(pair[0], pair[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		editions = append(editions, edition)
+	}
+	return editions
+}
+
+func TestSearchNewsInEditions(t *testing.T) {
+	server := newsapitest.NewServer()
+	defer server.Close()
+	articles := testArticles(server)
+	server.SetSearchNews("chips", newsapitest.Fixture{Articles: articles})
+	editions := multiTestEditions(t)
+
+	// the transport sends the source link requests to the server too
+	api := NewNewsApi(WithTransport(server.Transport()))
+	newsList, err := api.SearchNewsInEditions("chips", editions, WithRequestInterval(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newsList) != len(articles) {
+		t.Fatalf("got %d news, want %d merged news", len(newsList), len(articles))
+	}
+	for i, news := range newsList {
+		if len(news.Editions) != len(editions) {
+			t.Errorf("news %d: got editions %v, want all of them", i, news.Editions)
+		}
+		if news.SourceLink != articles[i].SourceURL {
+			t.Errorf("news %d: got source link %q", i, news.SourceLink)
+		}
+	}
+
+	if _, err := api.SearchNewsInEditions("", editions); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("empty query: got %v, want ErrEmptyQuery", err)
+	}
+	if _, err := api.GetTopicNewsInEditions(TopicBusiness, nil); !errors.Is(err, ErrEmptyEdition) {
+		t.Errorf("no edition: got %v, want ErrEmptyEdition", err)
+	}
+}
+
+func TestSearchNewsInEditionsErrors(t *testing.T) {
+	server := newsapitest.NewServer()
+	defer server.Close()
+	server.SetSearchNews("chips", newsapitest.Fixture{Articles: testArticles(server)})
+	editions := multiTestEditions(t)
+	api := NewNewsApi(WithBaseURL(server.BaseURL()))
+	options := []MultiEditionOption{WithRequestInterval(0), WithoutSourceLinkResolution()}
+
+	// the news of the other editions are returned along with the error
+	server.FailNext(1, newsapitest.Fixture{Status: http.StatusInternalServerError})
+	newsList, err := api.SearchNewsInEditions("chips", editions, options...)
+	var multiErr MultiEditionError
+	if !errors.As(err, &multiErr) || len(multiErr) != 1 || !errors.Is(multiErr[0], ErrUnexpectedStatus) {
+		t.Fatalf("one failed edition: got %v", err)
+	}
+	if len(newsList) != 2 || len(newsList[0].Editions) != len(editions)-1 {
+		t.Errorf("one failed edition: got %d news", len(newsList))
+	}
+
+	server.FailNext(len(editions), newsapitest.Fixture{Status: http.StatusInternalServerError})
+	newsList, err = api.SearchNewsInEditions("chips", editions, options...)
+	if !errors.As(err, &multiErr) || len(multiErr) != len(editions) || newsList != nil {
+		t.Errorf("every edition failed: got %d news, %v", len(newsList), err)
+	}
+}
+
+func TestMergeNews(t *testing.T) {
+	us, _ := LookupEdition(LanguageEnglish, LocationUnitedStates)
+	tw, _ := LookupEdition(LanguageChineseTraditional, LocationTaiwan)
+	newsList := []*News{
+		{GUID: "a", SourceLink: "https://www.example.com/chips?utm_source=feed", Editions: []Edition{us}, Queries: []string{"chips"}},
+		{GUID: "b", Link: "https://example.com/rain", Editions: []Edition{us}},
+		// the same source link without tracking parameters
+		{GUID: "c", SourceLink: "http://example.com/chips/", Editions: []Edition{tw}, Queries: []string{"tsmc"}},
+		// the same guid as the previous duplicate, with another link
+		{GUID: "c", Link: "https://example.com/other", Editions: []Edition{tw}, Queries: []string{"chips"}},
+		{GUID: "b", Editions: []Edition{tw}},
+	}
+	merged := MergeNews(newsList)
+	if len(merged) != 2 || merged[0] != newsList[0] || merged[1] != newsList[1] {
+		t.Fatalf("got %d news, want the first two", len(merged))
+	}
+	if len(merged[0].Editions) != 2 || len(merged[0].Queries) != 2 || merged[0].Queries[1] != "tsmc" {
+		t.Errorf("got editions %v and queries %v", merged[0].Editions, merged[0].Queries)
+	}
+	if len(merged[1].Editions) != 2 {
+		t.Errorf("got editions %v", merged[1].Editions)
+	}
+}
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		link, want string
+	}{
+		{"https://www.Example.com/news/chips/?utm_source=rss&utm_Medium=feed#top", "example.com/news/chips"},
+		{"http://example.com/news/chips", "example.com/news/chips"},
+		{"https://example.com/article?id=42&utm_campaign=x", "example.com/article?id=42"},
+		{"https://example.com/search?q=a+b&lang=en", "example.com/search?lang=en&q=a+b"},
+		{"not a url", "not a url"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := CanonicalURL(tt.link); got != tt.want {
+			t.Errorf("CanonicalURL(%q): got %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
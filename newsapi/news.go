@@ -13,30 +13,44 @@ import (
 
 var ()
 
+// News is a news article.
+// Its json encoding is stable, see WriteJSON and WriteNDJSON.
 type News struct {
-	Title           string
-	Description     string
-	Link            string
-	Links           []string
-	Content         string
-	Published       string
-	PublishedParsed *time.Time
-	Updated         string
-	UpdatedParsed   *time.Time
-	GUID            string
-	ImageURL        string
-	Categories      []string
-
-	SourceLink        string
-	SourceTitle       string
-	SourceImageURL    string
-	SourceImageWidth  int
-	SourceImageHeight int
-	SourceDescription string
-	SourceKeywords    []string
-	SourceSiteName    string
-	SourceIconUrl     string
-	SourceContent     string
+	Title           string     `json:"title"`
+	Description     string     `json:"description,omitempty"`
+	Link            string     `json:"link"`
+	Links           []string   `json:"links,omitempty"`
+	Content         string     `json:"content,omitempty"`
+	Published       string     `json:"published,omitempty"`
+	PublishedParsed *time.Time `json:"published_parsed,omitempty"`
+	Updated         string     `json:"updated,omitempty"`
+	UpdatedParsed   *time.Time `json:"updated_parsed,omitempty"`
+	GUID            string     `json:"guid,omitempty"`
+	ImageURL        string     `json:"image_url,omitempty"`
+	Categories      []string   `json:"categories,omitempty"`
+
+	SourceLink        string   `json:"source_link,omitempty"`
+	SourceTitle       string   `json:"source_title,omitempty"`
+	SourceImageURL    string   `json:"source_image_url,omitempty"`
+	SourceImageWidth  int      `json:"source_image_width,omitempty"`
+	SourceImageHeight int      `json:"source_image_height,omitempty"`
+	SourceDescription string   `json:"source_description,omitempty"`
+	SourceKeywords    []string `json:"source_keywords,omitempty"`
+	SourceSiteName    string   `json:"source_site_name,omitempty"`
+	SourceIconUrl     string   `json:"source_icon_url,omitempty"`
+	SourceContent     string   `json:"source_content,omitempty"`
+
+	// Topic is the topic of the query the news came from, if any
+	Topic string `json:"topic,omitempty"`
+	// Provider is the name of the provider the news came from, e.g. ProviderGoogle
+	Provider string `json:"provider,omitempty"`
+	// Editions are the editions the news was found in
+	Editions []Edition `json:"editions,omitempty"`
+	// Queries are the names of the aggregator queries the news matched
+	Queries []string `json:"queries,omitempty"`
+
+	// feedRank is the position of the news in its feed
+	feedRank int
 }
 
 func NewNews(item *gofeed.Item) *News {
@@ -53,6 +67,14 @@ func NewNews(item *gofeed.Item) *News {
 		Categories:      item.Categories,
 	}
 
+	// fall back to more date layouts when the feed parser fails
+	if n.PublishedParsed == nil {
+		n.PublishedParsed = ParseDate(n.Published)
+	}
+	if n.UpdatedParsed == nil {
+		n.UpdatedParsed = ParseDate(n.Updated)
+	}
+
 	if item.Image != nil {
 		n.ImageURL = item.Image.URL
 	}
@@ -60,30 +82,99 @@ func NewNews(item *gofeed.Item) *News {
 	return n
 }
 
-func (n *News) fetchSourceLink() error {
+// tagTopic sets the topic of the news
+func tagTopic(newsList []*News, topic string) {
+	for _, news := range newsList {
+		news.Topic = topic
+	}
+}
+
+// Publisher returns the name of the publisher of the news.
+// It is the source site name if fetched, the title suffix of google news, or the host of the source link.
+func (n *News) Publisher() string {
+	if n.SourceSiteName != "" {
+		return n.SourceSiteName
+	}
+	if n.Provider == ProviderGoogle {
+		if i := strings.LastIndex(n.Title, " - "); i > 0 {
+			return strings.TrimSpace(n.Title[i+3:])
+		}
+	}
+	link := n.SourceLink
+	if link == "" && !IsNewsApiLink(n.Link) {
+		link = n.Link
+	}
+	if u, err := url.Parse(link); err == nil && u.Host != "" {
+		return strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	}
+	return ""
+}
+
+// updatedOrPublished returns the updated date, or the published date if there is none
+func (n *News) updatedOrPublished() *time.Time {
+	if n.UpdatedParsed != nil {
+		return n.UpdatedParsed
+	}
+	return n.PublishedParsed
+}
+
+// publishedBetween checks whether the news was published in [from, to).
+// Zero times are unbounded, news without a published date only matches an unbounded range.
+func (n *News) publishedBetween(from, to time.Time) bool {
+	if from.IsZero() && to.IsZero() {
+		return true
+	}
+	if n.PublishedParsed == nil {
+		return false
+	}
+	if !from.IsZero() && n.PublishedParsed.Before(from) {
+		return false
+	}
+	if !to.IsZero() && !n.PublishedParsed.Before(to) {
+		return false
+	}
+	return true
+}
+
+// dedupKey returns the key identifying the same article, preferring the source link
+func (n *News) dedupKey() string {
+	switch {
+	case n.SourceLink != "":
+		return CanonicalURL(n.SourceLink)
+	case n.Link != "":
+		return CanonicalURL(n.Link)
+	default:
+		return n.GUID
+	}
+}
+
+func (n *News) fetchSourceLink(config *fetchConfig) error {
 	if n.SourceLink != "" {
 		return nil
 	}
 
 	// check if the link is a google news link
 	if IsNewsApiLink(n.Link) {
-		originalLink, err := GetOriginalLink(n.Link)
+		originalLink, err := getOriginalLink(n.Link, config)
 		if err != nil {
 			return fmt.Errorf("error getting original link: %w", err)
 		}
 		// set source link
 		n.SourceLink = originalLink
+	} else {
+		// links of other feeds already point to the source
+		n.SourceLink = n.Link
 	}
 	return nil
 }
 
-func (n *News) fetchSourceContent() error {
+func (n *News) fetchSourceContent(config *fetchConfig) error {
 	if n.SourceContent != "" {
 		return nil
 	}
 
 	if n.SourceLink == "" {
-		err := n.fetchSourceLink()
+		err := n.fetchSourceLink(config)
 		if err != nil {
 			return fmt.Errorf("error fetching source link: %s", err)
 		}
@@ -93,7 +184,7 @@ func (n *News) fetchSourceContent() error {
 	}
 
 	var content string
-	c := colly.NewCollector(colly.Async(true))
+	c := config.newCollector()
 	// remove script tag
 	c.OnHTML("script", func(e *colly.HTMLElement) {
 		e.DOM.Remove()
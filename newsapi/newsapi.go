@@ -2,27 +2,25 @@ package newsapi
 
 import (
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"net/url"
-	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gocolly/colly"
-	"github.com/mmcdole/gofeed"
 )
 
 var _ NewsApi = (*newsApi)(nil)
 
 var (
 	defaultNewsApi = &newsApi{
-		language: "en",
-		location: "US",
-		limit:    10,
-		client:   http.DefaultClient,
+		language:  "en",
+		location:  "US",
+		limit:     10,
+		client:    http.DefaultClient,
+		endpoints: DefaultEndpoints,
 	}
 
 	googleNewsURL = url.URL{
@@ -30,8 +28,30 @@ var (
 		Host:   "news.google.com",
 		Path:   "/",
 	}
+
+	// DefaultEndpoints are the endpoints of google news
+	DefaultEndpoints = Endpoints{
+		Top:    "rss",
+		Search: "rss/search",
+		Topic:  "rss/headlines/section/topic/" + topicVariable,
+		Geo:    "rss/headlines/section/geo/" + placeVariable,
+	}
+)
+
+const (
+	topicVariable = "{topic}"
+	placeVariable = "{place}"
 )
 
+// Endpoints are the paths of the feeds, relative to the base url.
+// Topic contains "{topic}", replaced by the topic id, and Geo contains "{place}", replaced by the escaped place.
+type Endpoints struct {
+	Top    string
+	Search string
+	Topic  string
+	Geo    string
+}
+
 type newsApi struct {
 	language  string
 	location  string
@@ -39,19 +59,38 @@ type newsApi struct {
 	startDate *time.Time
 	endDate   *time.Time
 	limit     int
+	sortOrder SortOrder
 	client    *http.Client
+	// tuning configures the *http.Transport of the client, see configureTransport
+	tuning    []func(t *http.Transport)
+	baseURL   *url.URL
+	endpoints Endpoints
+	headers   *Headers
+	recorder  *Recorder
+	warc      *WARCWriter
 }
 
 func NewNewsApi(options ...NewsApiOption) *newsApi {
-	n := defaultNewsApi
+	// a copy, so that the options of a client do not change the others
+	api := *defaultNewsApi
+	n := &api
 
 	for _, option := range options {
 		option(n)
 	}
+	// its own headers, so that the clients do not share a profile
+	if n.headers == nil {
+		n.headers = NewHeaders()
+	}
 
 	return n
 }
 
+// Name returns the provider name of google news
+func (n *newsApi) Name() string {
+	return ProviderGoogle
+}
+
 // SetQueryOptions sets the query options
 func (n *newsApi) SetQueryOptions(options ...QueryOption) {
 	for _, option := range options {
@@ -61,29 +100,39 @@ func (n *newsApi) SetQueryOptions(options ...QueryOption) {
 
 // GetTopNews gets the news by path and query
 func (n *newsApi) GetTopNews() ([]*News, error) {
-	return n.getNews("/rss", "")
+	return n.getNews(n.endpoints.Top, "")
 }
 
-// GetLocationNews gets the news by location
+// GetLocationNews gets the news by location, which may be a country code, region or city name
 func (n *newsApi) GetLocationNews(location string) ([]*News, error) {
 	if location == "" {
 		return nil, ErrEmptyLocation
 	}
-	path := "rss/headlines/section/geo/" + location
+	return n.GetPlaceNews(ParsePlace(location))
+}
+
+// GetPlaceNews gets the news by place
+func (n *newsApi) GetPlaceNews(place Place) ([]*News, error) {
+	if err := place.Validate(); err != nil {
+		return nil, err
+	}
+	path := strings.Replace(n.endpoints.Geo, placeVariable, place.PathSegment(), 1)
 	return n.getNews(path, "")
 }
 
 // GetTopicNews gets the news by topic
 func (n *newsApi) GetTopicNews(topic string) ([]*News, error) {
-	if topic == "" {
-		return nil, ErrEmptyTopic
+	topic, err := normalizeTopic(topic)
+	if err != nil {
+		return nil, err
 	}
-	topic = strings.ToUpper(topic)
-	if _, ok := TopicMap[topic]; !ok {
-		return nil, ErrInvalidTopic
+	path := strings.Replace(n.endpoints.Topic, topicVariable, topic, 1)
+	newsList, err := n.getNews(path, "")
+	if err != nil {
+		return nil, err
 	}
-	path := "rss/headlines/section/topic/" + topic
-	return n.getNews(path, "")
+	tagTopic(newsList, topic)
+	return newsList, nil
 }
 
 // SearchNews searches the news by query
@@ -92,17 +141,28 @@ func (n *newsApi) SearchNews(query string) ([]*News, error) {
 		return nil, ErrEmptyQuery
 	}
 	// query = strings.ReplaceAll(query, " ", "%20")
-	return n.getNews("rss/search", query)
+	return n.getNews(n.endpoints.Search, query)
 }
 
-// composeURL composes the url by path and query
-func (n *newsApi) composeURL(path string, query string) url.URL {
+// composeURL composes the url by edition, path and query
+func (n *newsApi) composeURL(edition Edition, path string, query string) url.URL {
 	searchURL := googleNewsURL
+	if n.baseURL != nil {
+		searchURL = *n.baseURL
+	}
 	q := url.Values{}
-	q.Add("hl", n.language)
-	q.Add("gl", n.location)
-	q.Add("ceid", n.location+":"+n.language)
-	searchURL.Path = path
+	q.Add("hl", edition.HL)
+	q.Add("gl", edition.Location)
+	q.Add("ceid", edition.CEID())
+	// the path is relative to the base url and may contain escaped segments, e.g. place names
+	base := strings.TrimSuffix(searchURL.EscapedPath(), "/") + "/"
+	path = base + strings.TrimPrefix(path, "/")
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		searchURL.Path = unescaped
+		searchURL.RawPath = path
+	} else {
+		searchURL.Path = path
+	}
 	if query != "" {
 		q.Set("q", query)
 		if n.period != nil {
@@ -121,39 +181,135 @@ func (n *newsApi) composeURL(path string, query string) url.URL {
 
 // getNews gets the news by path and query
 func (n *newsApi) getNews(path, query string) ([]*News, error) {
-	searchURL := n.composeURL(path, query)
-	req, err := http.NewRequest(http.MethodGet, searchURL.String(), nil)
+	edition, err := LookupEdition(n.language, n.location)
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+		return nil, err
 	}
-	req.Header.Set("User-Agent", RandomUserAgent())
+	searchURL := n.composeURL(edition, path, query)
+	return n.fetchNews(searchURL.String(), func(news *News) {
+		news.Provider = ProviderGoogle
+		news.Editions = []Edition{edition}
+	})
+}
 
-	resp, err := n.client.Do(req)
+// GetFeedNews gets the news of any rss, atom or json feed url.
+// The limit, sort order and date options apply as for google news.
+func (n *newsApi) GetFeedNews(feedURL string) ([]*News, error) {
+	if feedURL == "" {
+		return nil, ErrEmptyLink
+	}
+	u, err := url.Parse(feedURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidFeedURL
+	}
+	return n.fetchNews(feedURL, func(news *News) {
+		news.Provider = ProviderFeed
+		// publisher feeds link to the article itself
+		if !IsNewsApiLink(news.Link) {
+			news.SourceLink = news.Link
+		}
+	})
+}
+
+// HTTPClient returns the client of the feed requests, setting the browser headers of the language
+// and through the recorder and archiving them if set.
+// Use it with WithFetchClient to send the source link and source content requests the same way.
+func (n *newsApi) HTTPClient() *http.Client {
+	client := n.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	wrapped := *client
+	if n.recorder != nil {
+		wrapped.Transport = &recorderTransport{recorder: n.recorder, transport: wrapped.Transport}
+	}
+	if n.warc != nil {
+		wrapped.Transport = NewWARCTransport(wrapped.Transport, n.warc)
+	}
+	wrapped.Transport = NewHeadersTransport(wrapped.Transport, n.headers, editionLanguage(n.language, n.location))
+	return &wrapped
+}
+
+// editionLanguage returns the language tag of the edition, e.g. "pt-BR", the language if there is no such edition
+func editionLanguage(language, location string) string {
+	if edition, err := LookupEdition(language, location); err == nil && edition.HL != "" {
+		return edition.HL
+	}
+	return language
+}
+
+// copyClient returns a copy of the client, so that the options do not change a client shared with others
+func (n *newsApi) copyClient() *http.Client {
+	if n.client == nil {
+		return &http.Client{}
+	}
+	client := *n.client
+	return &client
+}
+
+// configureTransport configures a copy of the transport of the client, and the transports set later
+// by WithTransport and WithHTTPClient, so that the order of the options does not matter
+func (n *newsApi) configureTransport(configure func(t *http.Transport)) {
+	n.tuning = append(n.tuning[:len(n.tuning):len(n.tuning)], configure)
+	client := n.copyClient()
+	client.Transport = tuneTransport(client.Transport, configure)
+	n.client = client
+}
+
+// tuneTransport returns a configured copy of the transport, http.DefaultTransport if nil.
+// A *ProxyPool configures the transports of its proxies, and other transports are returned as is.
+func tuneTransport(transport http.RoundTripper, tuning ...func(t *http.Transport)) http.RoundTripper {
+	if len(tuning) == 0 {
+		return transport
+	}
+	var tuned *http.Transport
+	switch t := transport.(type) {
+	case nil:
+		tuned = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		tuned = t.Clone()
+	case *ProxyPool:
+		t.configureTransports(tuning...)
+		return t
+	default:
+		return transport
+	}
+	for _, configure := range tuning {
+		configure(tuned)
+	}
+	return tuned
+}
+
+// fetchNews fetches the feed and converts its items to news with tag applied,
+// which are then filtered by date, sorted and limited
+func (n *newsApi) fetchNews(feedURL string, tag func(news *News)) ([]*News, error) {
+	from, to, err := n.dateRange(time.Now())
 	if err != nil {
-		return nil, fmt.Errorf("error getting response: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	req, err := http.NewRequest(http.MethodGet, feedURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
-	parser := gofeed.NewParser()
-	feed, err := parser.ParseString(string(body))
+	items, err := GetFeedItems(n.HTTPClient(), req)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing response body: %w", err)
+		return nil, err
 	}
 
-	newsList := make([]*News, 0, len(feed.Items))
+	newsList := make([]*News, 0, len(items))
 
-	for _, item := range feed.Items {
+	for i, item := range items {
 		news := NewNews(item)
+		news.feedRank = i
+		tag(news)
+		// feeds other than search ignore the date operators, so filter every feed here
+		if !news.publishedBetween(from, to) {
+			continue
+		}
 		newsList = append(newsList, news)
 	}
-	// sort by published date
-	sort.Slice(newsList, func(i, j int) bool {
-		return newsList[i].PublishedParsed.After(*newsList[j].PublishedParsed)
-	})
+	SortNews(newsList, n.sortOrder)
 	// limit the number of news
 	if n.limit > 0 && n.limit < len(newsList) {
 		newsList = newsList[:n.limit]
@@ -161,36 +317,62 @@ func (n *newsApi) getNews(path, query string) ([]*News, error) {
 	return newsList, nil
 }
 
-// FetchSourceLinks fetches the source links by the google news links
-func FetchSourceLinks(newsList []*News) {
-	var wg sync.WaitGroup
-	for _, news := range newsList {
-		wg.Add(1)
-		go func(news *News) {
-			defer wg.Done()
-			err := news.fetchSourceLink()
-			if err != nil {
-				log.Println(fmt.Printf("error fetching source link: %s", err))
-			}
-		}(news)
+// dateRange returns the published date range set by the period, start date and end date options.
+// A zero time means the range is unbounded on that side.
+func (n *newsApi) dateRange(now time.Time) (from, to time.Time, err error) {
+	if n.period != nil {
+		if *n.period <= 0 {
+			return from, to, ErrInvalidPeriod
+		}
+		from = now.Add(-*n.period)
 	}
-	wg.Wait()
+	if n.startDate != nil && n.startDate.After(from) {
+		from = *n.startDate
+	}
+	if n.endDate != nil {
+		to = *n.endDate
+	}
+	if !from.IsZero() && from.After(now) {
+		return from, to, ErrInvalidDateRange
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return from, to, ErrInvalidDateRange
+	}
+	return from, to, nil
+}
+
+// FetchSourceLinks fetches the source links with the client of the api, see HTTPClient.
+// The options can still replace its transport, timeout and headers.
+func (n *newsApi) FetchSourceLinks(newsList []*News, options ...FetchOption) {
+	FetchSourceLinks(newsList, append([]FetchOption{WithFetchClient(n.HTTPClient())}, options...)...)
+}
+
+// FetchSourceContents fetches the source contents with the client of the api, see HTTPClient.
+// The options can still replace its transport, timeout and headers.
+func (n *newsApi) FetchSourceContents(newsList []*News, options ...FetchOption) {
+	FetchSourceContents(newsList, append([]FetchOption{WithFetchClient(n.HTTPClient())}, options...)...)
+}
+
+// FetchSourceLinks fetches the source links by the google news links
+func FetchSourceLinks(newsList []*News, options ...FetchOption) {
+	config := newFetchConfig(options)
+	config.each(newsList, func(news *News) {
+		err := news.fetchSourceLink(config)
+		if err != nil {
+			log.Println(fmt.Printf("error fetching source link: %s", err))
+		}
+	})
 }
 
 // FetchSourceContents fetches the source contents by the source links
-func FetchSourceContents(newsList []*News) {
-	var wg sync.WaitGroup
-	for _, news := range newsList {
-		wg.Add(1)
-		go func(news *News) {
-			defer wg.Done()
-			err := news.fetchSourceContent()
-			if err != nil {
-				log.Println(fmt.Printf("error fetching source content: %s", err))
-			}
-		}(news)
-	}
-	wg.Wait()
+func FetchSourceContents(newsList []*News, options ...FetchOption) {
+	config := newFetchConfig(options)
+	config.each(newsList, func(news *News) {
+		err := news.fetchSourceContent(config)
+		if err != nil {
+			log.Println(fmt.Printf("error fetching source content: %s", err))
+		}
+	})
 }
 
 // Deprecated: use FetchSourceContents instead
@@ -1,10 +1,26 @@
 package newsapi
 
-type NewsApi interface {
+// Provider is a source of news, see NewsApi for google news
+type Provider interface {
+	Name() string
 	GetTopNews() ([]*News, error)
 	GetTopicNews(topic string) ([]*News, error)
-	GetLocationNews(location string) ([]*News, error)
 	SearchNews(query string) ([]*News, error)
+}
+
+type NewsApi interface {
+	Provider
+
+	GetLocationNews(location string) ([]*News, error)
+	GetPlaceNews(place Place) ([]*News, error)
+	GetFeedNews(feedURL string) ([]*News, error)
+
+	SearchNewsInEditions(query string, editions []Edition, options ...MultiEditionOption) ([]*News, error)
+	GetTopicNewsInEditions(topic string, editions []Edition, options ...MultiEditionOption) ([]*News, error)
+
+	// FetchSourceLinks and FetchSourceContents send the requests with the client of the api
+	FetchSourceLinks(newsList []*News, options ...FetchOption)
+	FetchSourceContents(newsList []*News, options ...FetchOption)
 
 	SetQueryOptions(options ...QueryOption)
 }
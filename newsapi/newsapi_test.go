@@ -0,0 +1,154 @@
+package newsapi
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Zhima-Mochi/newsApi-go/newsapi/newsapitest"
+)
+
+func testArticles(server *newsapitest.Server) []newsapitest.Article {
+	now := time.Now().Truncate(time.Second)
+	return []newsapitest.Article{
+		{Title: "Chip exports rise", Publisher: "Reuters", PublisherURL: "https://www.reuters.com", SourceURL: server.URL + "/articles/chips", Published: now.Add(-time.Hour)},
+		{Title: "Markets close higher", Publisher: "AP News", PublisherURL: "https://apnews.com", SourceURL: server.URL + "/articles/markets", Published: now.Add(-2 * time.Hour)},
+	}
+}
+
+func newTestNewsApi(server *newsapitest.Server, options ...NewsApiOption) NewsApi {
+	return NewNewsApi(append([]NewsApiOption{WithBaseURL(server.BaseURL())}, options...)...)
+}
+
+func TestGetNews(t *testing.T) {
+	server := newsapitest.NewServer()
+	defer server.Close()
+	articles := testArticles(server)
+	server.SetTopNews(newsapitest.Fixture{Articles: articles})
+	server.SetTopicNews("BUSINESS", newsapitest.Fixture{Articles: articles[1:]})
+	server.SetSearchNews("chips", newsapitest.Fixture{Articles: articles[:1]})
+	server.SetGeoNews("Taipei", newsapitest.Fixture{Articles: articles})
+
+	api := newTestNewsApi(server)
+	tests := []struct {
+		name  string
+		fetch func() ([]*News, error)
+		want  []string
+	}{
+		{"top", api.GetTopNews, []string{"Chip exports rise - Reuters", "Markets close higher - AP News"}},
+		{"topic", func() ([]*News, error) { return api.GetTopicNews("business") }, []string{"Markets close higher - AP News"}},
+		{"search", func() ([]*News, error) { return api.SearchNews("chips") }, []string{"Chip exports rise - Reuters"}},
+		{"location", func() ([]*News, error) { return api.GetLocationNews("Taipei") }, []string{"Chip exports rise - Reuters", "Markets close higher - AP News"}},
+	}
+	for _, tt := range tests {
+		newsList, err := tt.fetch()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(newsList) != len(tt.want) {
+			t.Fatalf("%s: got %d news, want %d", tt.name, len(newsList), len(tt.want))
+		}
+		for i, news := range newsList {
+			if news.Title != tt.want[i] {
+				t.Errorf("%s: news %d: got %q, want %q", tt.name, i, news.Title, tt.want[i])
+			}
+			if news.Provider != ProviderGoogle || len(news.Editions) != 1 || news.Editions[0].Location != "US" {
+				t.Errorf("%s: news %d: got provider %q and editions %v", tt.name, i, news.Provider, news.Editions)
+			}
+		}
+	}
+	if newsList, _ := api.GetTopicNews("business"); newsList[0].Topic != TopicBusiness {
+		t.Errorf("topic: got %q, want %q", newsList[0].Topic, TopicBusiness)
+	}
+
+	requests := server.Requests()
+	if len(requests) != len(tests)+1 {
+		t.Fatalf("requests: got %d, want %d", len(requests), len(tests)+1)
+	}
+	for _, req := range requests {
+		if req.Query.Get("hl") != "en-US" || req.Query.Get("gl") != "US" || req.Query.Get("ceid") != "US:en" {
+			t.Errorf("request %s: got query %v", req.Path, req.Query)
+		}
+		if req.Header.Get("User-Agent") == "" || req.Header.Get("Accept-Language") == "" {
+			t.Errorf("request %s: got no browser headers: %v", req.Path, req.Header)
+		}
+	}
+}
+
+func TestNewsApiHeaders(t *testing.T) {
+	if NewNewsApi().headers == NewNewsApi().headers {
+		t.Error("two clients share their headers")
+	}
+	headers := NewHeaders()
+	if api := NewNewsApi(WithHeaders(headers)); api.headers != headers {
+		t.Error("the headers option was not applied")
+	}
+	if p := NewFeedProvider(nil); p.api.headers != p.headers {
+		t.Error("the feed provider does not read the feeds with its headers")
+	}
+}
+
+func TestGetNewsErrors(t *testing.T) {
+	server := newsapitest.NewServer()
+	defer server.Close()
+	api := newTestNewsApi(server)
+
+	server.FailNext(1, newsapitest.RateLimited(time.Minute))
+	if _, err := api.GetTopNews(); !errors.Is(err, ErrUnexpectedStatus) {
+		t.Errorf("rate limited: got %v, want ErrUnexpectedStatus", err)
+	}
+	if _, err := api.GetTopicNews("sports-ish"); !errors.Is(err, ErrInvalidTopic) {
+		t.Errorf("invalid topic: got %v, want ErrInvalidTopic", err)
+	}
+	if _, err := api.SearchNews(""); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("empty query: got %v, want ErrEmptyQuery", err)
+	}
+	server.SetTopNews(newsapitest.Fixture{Body: "not a feed"})
+	if _, err := api.GetTopNews(); err == nil {
+		t.Error("invalid feed: got no error")
+	}
+}
+
+func TestGetNewsLimitAndPeriod(t *testing.T) {
+	server := newsapitest.NewServer()
+	defer server.Close()
+	articles := testArticles(server)
+	articles = append(articles, newsapitest.Article{Title: "Old news", SourceURL: server.URL + "/articles/old", Published: time.Now().Add(-72 * time.Hour)})
+	server.SetTopNews(newsapitest.Fixture{Articles: articles})
+
+	api := newTestNewsApi(server)
+	api.SetQueryOptions(WithPeriod(24*time.Hour), WithLimit(1))
+	newsList, err := api.GetTopNews()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newsList) != 1 || newsList[0].Title != "Chip exports rise - Reuters" {
+		t.Errorf("got %d news", len(newsList))
+	}
+}
+
+func TestFetchSourceLinks(t *testing.T) {
+	server := newsapitest.NewServer()
+	defer server.Close()
+	articles := testArticles(server)
+	server.SetTopNews(newsapitest.Fixture{Articles: articles})
+	server.SetPage("/articles/chips", newsapitest.Fixture{Body: `<html><head><title>Chip exports rise</title>
+<meta property="og:site_name" content="Reuters"><meta property="og:description" content="Exports of chips rose."></head>
+<body><p>Exports of chips rose again.</p></body></html>`})
+
+	api := NewNewsApi(WithTransport(server.Transport()))
+	newsList, err := api.GetTopNews()
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.FetchSourceLinks(newsList)
+	for i, news := range newsList {
+		if news.SourceLink != articles[i].SourceURL {
+			t.Errorf("news %d: got source link %q, want %q", i, news.SourceLink, articles[i].SourceURL)
+		}
+	}
+	api.FetchSourceContents(newsList[:1])
+	if newsList[0].SourceSiteName != "Reuters" || newsList[0].SourceDescription != "Exports of chips rose." {
+		t.Errorf("got source contents %+v", newsList[0])
+	}
+}
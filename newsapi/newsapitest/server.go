@@ -0,0 +1,416 @@
+// Package newsapitest provides a fake google news server for integration tests.
+//
+// The server answers the rss endpoints of google news with feeds built from
+// programmable fixtures, and the article links of those feeds with redirect
+// pages to the source links, so that errors, rate limits and odd feeds can be
+// simulated without the network.
+package newsapitest
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	rssType  = "application/xml; charset=utf-8"
+	htmlType = "text/html; charset=utf-8"
+)
+
+// Article is a news article of a fixture feed
+type Article struct {
+	Title     string
+	Publisher string
+	// PublisherURL is the site of the publisher, the url of the source element
+	PublisherURL string
+	// SourceURL is the link of the article, where its redirect page points to
+	SourceURL   string
+	Description string
+	Published   time.Time
+	// ID is the id of the google news link, derived from the source url if empty
+	ID string
+}
+
+// id returns the id of the google news link of the article
+func (a Article) id() string {
+	if a.ID != "" {
+		return a.ID
+	}
+	return "CBMi" + base64.RawURLEncoding.EncodeToString([]byte(a.SourceURL))
+}
+
+// Fixture is the response of the server to a request
+type Fixture struct {
+	// Articles are the items of the feed
+	Articles []Article
+	// Status is the status code, 200 by default
+	Status int
+	// Header is added to the response, e.g. a Retry-After header
+	Header http.Header
+	// Body replaces the generated feed when not empty, e.g. to serve an invalid feed
+	Body string
+	// Delay delays the response, e.g. to trigger client timeouts
+	Delay time.Duration
+}
+
+// RateLimited returns a fixture answering 429 Too Many Requests with a Retry-After header
+func RateLimited(retryAfter time.Duration) Fixture {
+	return Fixture{
+		Status: http.StatusTooManyRequests,
+		Header: http.Header{"Retry-After": {strconv.Itoa(int(retryAfter / time.Second))}},
+	}
+}
+
+// Request is a request received by the server
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+}
+
+// Server is a fake google news server
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	top      *Fixture
+	topics   map[string]Fixture
+	geo      map[string]Fixture
+	searches map[string]Fixture
+	pages    map[string]Fixture
+	fallback *Fixture
+	failures []Fixture
+	articles map[string]Article
+	requests []Request
+}
+
+// NewServer starts a server with empty feeds, which is closed by Close
+func NewServer() *Server {
+	s := &Server{
+		topics:   map[string]Fixture{},
+		geo:      map[string]Fixture{},
+		searches: map[string]Fixture{},
+		pages:    map[string]Fixture{},
+		articles: map[string]Article{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// BaseURL returns the url of the server, see newsapi.WithBaseURL
+func (s *Server) BaseURL() *url.URL {
+	u, _ := url.Parse(s.URL)
+	return u
+}
+
+// SetTopNews sets the response of /rss
+func (s *Server) SetTopNews(fixture Fixture) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.top = &fixture
+	s.addArticles(fixture)
+}
+
+// SetTopicNews sets the response of /rss/headlines/section/topic/{topic}, e.g. "BUSINESS"
+func (s *Server) SetTopicNews(topic string, fixture Fixture) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.topics[topic] = fixture
+	s.addArticles(fixture)
+}
+
+// SetGeoNews sets the response of /rss/headlines/section/geo/{place}, the place as in its unescaped path segment
+func (s *Server) SetGeoNews(place string, fixture Fixture) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.geo[place] = fixture
+	s.addArticles(fixture)
+}
+
+// SetSearchNews sets the response of /rss/search for the query, whatever date operators follow it
+func (s *Server) SetSearchNews(query string, fixture Fixture) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.searches[query] = fixture
+	s.addArticles(fixture)
+}
+
+// SetPage sets the response of any other path, e.g. an article page of a source url on the server.
+// The body of the fixture is served as html.
+func (s *Server) SetPage(path string, fixture Fixture) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages[path] = fixture
+}
+
+// SetDefault sets the response of the feeds without a fixture, an empty feed by default
+func (s *Server) SetDefault(fixture Fixture) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallback = &fixture
+	s.addArticles(fixture)
+}
+
+// FailNext answers the next n requests with the fixture instead, e.g. RateLimited(time.Minute)
+func (s *Server) FailNext(n int, fixture Fixture) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.failures = append(s.failures, fixture)
+	}
+}
+
+// Requests returns the requests received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Reset removes the fixtures and the received requests
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.top = nil
+	s.fallback = nil
+	s.failures = nil
+	s.requests = nil
+	s.topics = map[string]Fixture{}
+	s.geo = map[string]Fixture{}
+	s.searches = map[string]Fixture{}
+	s.pages = map[string]Fixture{}
+	s.articles = map[string]Article{}
+}
+
+// ArticleLink returns the google news link of the article, as in the feeds of the server
+func ArticleLink(article Article) string {
+	return "https://news.google.com/rss/articles/" + article.id() + "?oc=5"
+}
+
+func (s *Server) addArticles(fixture Fixture) {
+	for _, article := range fixture.Articles {
+		s.articles[article.id()] = article
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+	})
+	if len(s.failures) > 0 {
+		fixture := s.failures[0]
+		s.failures = s.failures[1:]
+		s.mu.Unlock()
+		writeFixture(w, r, fixture, htmlType, fixture.Body)
+		return
+	}
+	fixture, body, contentType, ok := s.route(r)
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeFixture(w, r, fixture, contentType, body)
+}
+
+// route returns the fixture of the request and its body
+func (s *Server) route(r *http.Request) (Fixture, string, string, bool) {
+	path := r.URL.Path
+	switch {
+	case path == "/rss" || path == "/rss/":
+		return s.feed(s.top, "Top stories", r)
+	case path == "/rss/search":
+		q := r.URL.Query().Get("q")
+		return s.feed(s.search(q), strconv.Quote(q), r)
+	case strings.HasPrefix(path, "/rss/headlines/section/topic/"):
+		topic := strings.TrimPrefix(path, "/rss/headlines/section/topic/")
+		return s.feed(lookup(s.topics, topic), topic, r)
+	case strings.HasPrefix(path, "/rss/headlines/section/geo/"):
+		place := strings.TrimPrefix(path, "/rss/headlines/section/geo/")
+		return s.feed(lookup(s.geo, place), place, r)
+	case strings.HasPrefix(path, "/rss/articles/"):
+		article, ok := s.articles[strings.TrimPrefix(path, "/rss/articles/")]
+		if !ok {
+			return Fixture{}, "", "", false
+		}
+		return Fixture{}, redirectPage(article), htmlType, true
+	}
+	if fixture, ok := s.pages[path]; ok {
+		return fixture, fixture.Body, htmlType, true
+	}
+	return Fixture{}, "", "", false
+}
+
+func lookup(fixtures map[string]Fixture, key string) *Fixture {
+	if fixture, ok := fixtures[key]; ok {
+		return &fixture
+	}
+	return nil
+}
+
+// search returns the fixture of the query, ignoring the date operators appended to it
+func (s *Server) search(q string) *Fixture {
+	if fixture := lookup(s.searches, q); fixture != nil {
+		return fixture
+	}
+	for query := range s.searches {
+		if strings.HasPrefix(q, query+"+") || strings.HasPrefix(q, query+" ") {
+			return lookup(s.searches, query)
+		}
+	}
+	return nil
+}
+
+// feed returns the fixture, or the default one if nil, with the generated feed as body
+func (s *Server) feed(fixture *Fixture, title string, r *http.Request) (Fixture, string, string, bool) {
+	if fixture == nil {
+		fixture = s.fallback
+	}
+	if fixture == nil {
+		fixture = &Fixture{}
+	}
+	if fixture.Body != "" {
+		return *fixture, fixture.Body, rssType, true
+	}
+	return *fixture, renderFeed(title, r, fixture.Articles), rssType, true
+}
+
+func writeFixture(w http.ResponseWriter, r *http.Request, fixture Fixture, contentType string, body string) {
+	if fixture.Delay > 0 {
+		select {
+		case <-time.After(fixture.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	for key, values := range fixture.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	status := fixture.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	fmt.Fprint(w, body)
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Language    string    `xml:"language,omitempty"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	GUID        rssGUID   `xml:"guid"`
+	PubDate     string    `xml:"pubDate,omitempty"`
+	Description string    `xml:"description"`
+	Source      rssSource `xml:"source"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssSource struct {
+	URL   string `xml:"url,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+// renderFeed renders the articles as google news does, the publisher appended to the titles
+func renderFeed(title string, r *http.Request, articles []Article) string {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       title + " - Google News",
+			Link:        "https://news.google.com/?" + r.URL.RawQuery,
+			Language:    r.URL.Query().Get("hl"),
+			Description: "Google News",
+		},
+	}
+	for _, article := range articles {
+		link := ArticleLink(article)
+		item := rssItem{
+			Title: article.Title,
+			Link:  link,
+			GUID:  rssGUID{Value: article.id()},
+			Description: fmt.Sprintf(`<a href="%s" target="_blank">%s</a>&nbsp;&nbsp;<font color="#6f6f6f">%s</font>`,
+				html.EscapeString(link), html.EscapeString(article.Title), html.EscapeString(article.Publisher)),
+			Source: rssSource{URL: article.PublisherURL, Value: article.Publisher},
+		}
+		if article.Publisher != "" {
+			item.Title += " - " + article.Publisher
+		}
+		if article.Description != "" {
+			item.Description = article.Description
+		}
+		if !article.Published.IsZero() {
+			item.PubDate = article.Published.UTC().Format(http.TimeFormat)
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return xml.Header + string(data)
+}
+
+// redirectPage renders the page of a google news link, whose last link is the source url
+func redirectPage(article Article) string {
+	source := html.EscapeString(article.SourceURL)
+	return `<!DOCTYPE html><html><head><title>Google News</title>` +
+		`<noscript><meta content="0;url=` + source + `" http-equiv="refresh"></noscript></head>` +
+		`<body><a href="` + source + `" rel="nofollow">` + source + `</a></body></html>`
+}
+
+// Transport returns a transport sending the requests to news.google.com to the server instead,
+// e.g. for the redirect pages with newsapi.WithFetchTransport. The other requests are sent as usual.
+func (s *Server) Transport() http.RoundTripper {
+	return &rewriteTransport{target: s.BaseURL(), transport: s.Client().Transport}
+}
+
+type rewriteTransport struct {
+	target    *url.URL
+	transport http.RoundTripper
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := strings.TrimPrefix(req.URL.Hostname(), "www.")
+	if host != "news.google.com" {
+		return t.transport.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	req.Host = t.target.Host
+	return t.transport.RoundTrip(req)
+}
@@ -0,0 +1,515 @@
+package newsapi
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultSinkRetries    = 3
+	defaultSinkRetryDelay = time.Second
+	defaultSinkTimeout    = 10 * time.Second
+
+	// SignatureHeader is the header of the hmac-sha256 signature of webhook payloads
+	SignatureHeader = "X-Newsapi-Signature"
+
+	// telegramMaxMessageLength is the maximal length of a telegram message
+	telegramMaxMessageLength = 4096
+)
+
+var (
+	_ Sink = (*webhookSink)(nil)
+	_ Sink = (*slackSink)(nil)
+	_ Sink = (*telegramSink)(nil)
+
+	telegramAPIURL = url.URL{
+		Scheme: "https",
+		Host:   "api.telegram.org",
+		Path:   "/",
+	}
+)
+
+// Sink sends notifications of news
+type Sink interface {
+	Send(newsList []*News) error
+}
+
+// sinkConfig is the configuration shared by the sinks
+type sinkConfig struct {
+	client     *http.Client
+	retries    int
+	retryDelay time.Duration
+	batchSize  int
+	apiURL     url.URL
+	// redact hides the credentials of the request url from the errors, e.g. the telegram bot token
+	redact func(target string) string
+}
+
+type SinkOption func(*sinkConfig)
+
+// WithSinkClient sets the http client of the sink
+func WithSinkClient(client *http.Client) SinkOption {
+	return func(c *sinkConfig) {
+		c.client = client
+	}
+}
+
+// WithRetries sets how many times a failed request is retried
+func WithRetries(retries int) SinkOption {
+	return func(c *sinkConfig) {
+		if retries >= 0 {
+			c.retries = retries
+		}
+	}
+}
+
+// WithRetryDelay sets the delay before the first retry, doubled for every retry
+func WithRetryDelay(delay time.Duration) SinkOption {
+	return func(c *sinkConfig) {
+		c.retryDelay = delay
+	}
+}
+
+// WithBatchSize sets the maximal number of news sent in one request
+func WithBatchSize(size int) SinkOption {
+	return func(c *sinkConfig) {
+		if size > 0 {
+			c.batchSize = size
+		}
+	}
+}
+
+// WithTelegramAPIURL sets the base url of the telegram bot api, e.g. a local httptest server
+func WithTelegramAPIURL(apiURL *url.URL) SinkOption {
+	return func(c *sinkConfig) {
+		c.apiURL = *apiURL
+	}
+}
+
+func newSinkConfig(batchSize int, options []SinkOption) *sinkConfig {
+	c := &sinkConfig{
+		client:     &http.Client{Timeout: defaultSinkTimeout},
+		retries:    defaultSinkRetries,
+		retryDelay: defaultSinkRetryDelay,
+		batchSize:  batchSize,
+		apiURL:     telegramAPIURL,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// sendBatches calls send with every batch of the news
+func (c *sinkConfig) sendBatches(newsList []*News, send func(batch []*News) error) error {
+	for start := 0; start < len(newsList); start += c.batchSize {
+		end := start + c.batchSize
+		if end > len(newsList) {
+			end = len(newsList)
+		}
+		if err := send(newsList[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// post posts the json body to the url, retrying on network errors, 429 and 5xx responses
+func (c *sinkConfig) post(target string, body []byte, header http.Header) error {
+	delay := c.retryDelay
+	var lastErr error
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+		req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("error creating request: %w", c.redactError(err))
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("error getting response: %w", c.redactError(err))
+			continue
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+				delay = time.Duration(seconds) * time.Second
+			}
+			continue
+		}
+		if resp.StatusCode < 500 {
+			return lastErr
+		}
+	}
+	return lastErr
+}
+
+// redactError hides the credentials of the url of a *url.Error
+func (c *sinkConfig) redactError(err error) error {
+	var urlErr *url.Error
+	if c.redact != nil && errors.As(err, &urlErr) {
+		urlErr.URL = c.redact(urlErr.URL)
+	}
+	return err
+}
+
+// redactURLPath keeps only the scheme and host of a webhook url, whose path, query or user info are its credentials
+func redactURLPath(target string) string {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return "REDACTED"
+	}
+	return u.Scheme + "://" + u.Host + "/REDACTED"
+}
+
+// notificationNews is the json payload of a news sent by the webhook sink
+type notificationNews struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Link        string     `json:"link"`
+	Publisher   string     `json:"publisher,omitempty"`
+	Published   *time.Time `json:"published,omitempty"`
+	ImageURL    string     `json:"image_url,omitempty"`
+	Topic       string     `json:"topic,omitempty"`
+	Queries     []string   `json:"queries,omitempty"`
+}
+
+// notificationLink returns the source link of the news, or its link if unresolved
+func notificationLink(news *News) string {
+	if news.SourceLink != "" {
+		return news.SourceLink
+	}
+	return news.Link
+}
+
+// notificationImage returns the source image of the news, or its feed image
+func notificationImage(news *News) string {
+	if news.SourceImageURL != "" {
+		return news.SourceImageURL
+	}
+	return news.ImageURL
+}
+
+// webhookSink posts the news as json to a url
+type webhookSink struct {
+	*sinkConfig
+	url        string
+	signingKey string
+}
+
+// NewWebhookSink returns a sink posting {"news": [...]} to the url.
+// If signingKey is not empty, the body is signed with hmac-sha256 in the SignatureHeader as "sha256=<hex>".
+// The errors do not show the path and query of the url.
+func NewWebhookSink(url, signingKey string, options ...SinkOption) *webhookSink {
+	s := &webhookSink{
+		sinkConfig: newSinkConfig(20, options),
+		url:        url,
+		signingKey: signingKey,
+	}
+	s.redact = redactURLPath
+	return s
+}
+
+func (s *webhookSink) Send(newsList []*News) error {
+	return s.sendBatches(newsList, func(batch []*News) error {
+		payload := struct {
+			News []notificationNews `json:"news"`
+		}{News: make([]notificationNews, 0, len(batch))}
+		for _, news := range batch {
+			payload.News = append(payload.News, notificationNews{
+				Title:       news.Title,
+				Description: news.Description,
+				Link:        notificationLink(news),
+				Publisher:   news.Publisher(),
+				Published:   news.PublishedParsed,
+				ImageURL:    notificationImage(news),
+				Topic:       news.Topic,
+				Queries:     news.Queries,
+			})
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("error encoding payload: %w", err)
+		}
+		header := http.Header{}
+		if s.signingKey != "" {
+			header.Set(SignatureHeader, SignPayload(s.signingKey, body))
+		}
+		return s.post(s.url, body, header)
+	})
+}
+
+// SignPayload returns the signature of a webhook body as "sha256=<hex>", for verifying received webhooks
+func SignPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// slackSink posts the news to a slack incoming webhook
+type slackSink struct {
+	*sinkConfig
+	webhookURL string
+}
+
+// NewSlackSink returns a sink posting the news to a slack incoming webhook url.
+// The errors do not show the path of the url, which is its token.
+func NewSlackSink(webhookURL string, options ...SinkOption) *slackSink {
+	s := &slackSink{
+		sinkConfig: newSinkConfig(10, options),
+		webhookURL: webhookURL,
+	}
+	s.redact = redactURLPath
+	return s
+}
+
+// slackEscape escapes the characters with a special meaning in slack mrkdwn
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// slackEscapeURL percent-encodes the characters ending a slack mrkdwn link in its url
+func slackEscapeURL(link string) string {
+	return strings.NewReplacer("|", "%7C", "<", "%3C", ">", "%3E").Replace(link)
+}
+
+func (s *slackSink) Send(newsList []*News) error {
+	return s.sendBatches(newsList, func(batch []*News) error {
+		type text struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		}
+		type accessory struct {
+			Type     string `json:"type"`
+			ImageURL string `json:"image_url"`
+			AltText  string `json:"alt_text"`
+		}
+		type block struct {
+			Type      string     `json:"type"`
+			Text      *text      `json:"text,omitempty"`
+			Accessory *accessory `json:"accessory,omitempty"`
+		}
+		payload := struct {
+			Text   string  `json:"text"`
+			Blocks []block `json:"blocks"`
+		}{}
+
+		titles := make([]string, 0, len(batch))
+		for i, news := range batch {
+			titles = append(titles, news.Title)
+			line := fmt.Sprintf("*<%s|%s>*", slackEscapeURL(notificationLink(news)), slackEscape(news.Title))
+			var meta []string
+			if publisher := news.Publisher(); publisher != "" {
+				meta = append(meta, slackEscape(publisher))
+			}
+			if news.PublishedParsed != nil {
+				meta = append(meta, fmt.Sprintf("<!date^%d^{date_short_pretty} {time}|%s>", news.PublishedParsed.Unix(), news.PublishedParsed.UTC().Format(time.RFC1123)))
+			}
+			if len(meta) > 0 {
+				line += "\n" + strings.Join(meta, " · ")
+			}
+			b := block{Type: "section", Text: &text{Type: "mrkdwn", Text: line}}
+			if image := notificationImage(news); image != "" {
+				b.Accessory = &accessory{Type: "image", ImageURL: image, AltText: news.Title}
+			}
+			if i > 0 {
+				payload.Blocks = append(payload.Blocks, block{Type: "divider"})
+			}
+			payload.Blocks = append(payload.Blocks, b)
+		}
+		// text is the fallback shown in notifications
+		payload.Text = strings.Join(titles, "\n")
+
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("error encoding payload: %w", err)
+		}
+		return s.post(s.webhookURL, body, nil)
+	})
+}
+
+// telegramSink sends the news as messages of a telegram bot
+type telegramSink struct {
+	*sinkConfig
+	botToken string
+	chatID   string
+}
+
+// NewTelegramSink returns a sink sending the news to a chat with the telegram bot api
+func NewTelegramSink(botToken, chatID string, options ...SinkOption) *telegramSink {
+	s := &telegramSink{
+		sinkConfig: newSinkConfig(5, options),
+		botToken:   botToken,
+		chatID:     chatID,
+	}
+	if botToken != "" {
+		s.redact = func(target string) string {
+			return strings.ReplaceAll(target, botToken, "REDACTED")
+		}
+	}
+	return s
+}
+
+// telegramMessages formats the news as telegram html, split into messages of the maximal message length
+func telegramMessages(batch []*News) []string {
+	var messages []string
+	message := ""
+	for _, news := range batch {
+		entry := fmt.Sprintf(`<b><a href="%s">%s</a></b>`, html.EscapeString(notificationLink(news)), html.EscapeString(news.Title))
+		if publisher := news.Publisher(); publisher != "" {
+			entry += "\n<i>" + html.EscapeString(publisher) + "</i>"
+		}
+		next := entry
+		if message != "" {
+			next = message + "\n\n" + entry
+		}
+		if utf8.RuneCountInString(next) <= telegramMaxMessageLength {
+			message = next
+			continue
+		}
+		if message != "" {
+			messages = append(messages, message)
+		}
+		// an entry longer than a message is only possible with an absurd link or title, fall back to the title.
+		// Escaping expands a rune to at most 6 runes, e.g. "&quot;".
+		if utf8.RuneCountInString(entry) > telegramMaxMessageLength {
+			title := []rune(news.Title)
+			if len(title) > telegramMaxMessageLength/6 {
+				title = title[:telegramMaxMessageLength/6]
+			}
+			entry = html.EscapeString(string(title))
+		}
+		message = entry
+	}
+	if message != "" {
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+func (s *telegramSink) Send(newsList []*News) error {
+	target := s.apiURL
+	target.Path = strings.TrimSuffix(target.Path, "/") + "/bot" + s.botToken + "/sendMessage"
+	return s.sendBatches(newsList, func(batch []*News) error {
+		for _, message := range telegramMessages(batch) {
+			payload := map[string]interface{}{
+				"chat_id":    s.chatID,
+				"text":       message,
+				"parse_mode": "HTML",
+				// a preview is only useful for a single news
+				"disable_web_page_preview": len(batch) > 1,
+			}
+			body, err := json.Marshal(payload)
+			if err != nil {
+				return fmt.Errorf("error encoding payload: %w", err)
+			}
+			if err := s.post(target.String(), body, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Notifier sends the news to every sink
+type Notifier struct {
+	sinks []Sink
+}
+
+// NewNotifier returns a notifier for the sinks
+func NewNotifier(sinks ...Sink) *Notifier {
+	return &Notifier{sinks: sinks}
+}
+
+// SinkError is the error of a single sink of a notifier
+type SinkError struct {
+	// Sink is the index of the sink in the notifier
+	Sink int
+	Err  error
+}
+
+func (e *SinkError) Error() string {
+	return fmt.Sprintf("sink %d: %s", e.Sink, e.Err)
+}
+
+func (e *SinkError) Unwrap() error {
+	return e.Err
+}
+
+// SinkErrors collects the errors of the failed sinks of a notifier
+type SinkErrors []*SinkError
+
+func (e SinkErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d sink(s) failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Notify sends the news to every sink concurrently
+func (n *Notifier) Notify(newsList []*News) error {
+	if len(newsList) == 0 {
+		return nil
+	}
+	errs := make([]error, len(n.sinks))
+	var wg sync.WaitGroup
+	for i, sink := range n.sinks {
+		wg.Add(1)
+		go func(i int, sink Sink) {
+			defer wg.Done()
+			errs[i] = sink.Send(newsList)
+		}(i, sink)
+	}
+	wg.Wait()
+
+	var sinkErrs SinkErrors
+	for i, err := range errs {
+		if err != nil {
+			sinkErrs = append(sinkErrs, &SinkError{Sink: i, Err: err})
+		}
+	}
+	if len(sinkErrs) > 0 {
+		return sinkErrs
+	}
+	return nil
+}
+
+// NotifyAlerts sends the news of the alerts, each news once
+func (n *Notifier) NotifyAlerts(alerts []*Alert) error {
+	seen := map[*News]bool{}
+	var newsList []*News
+	for _, alert := range alerts {
+		if !seen[alert.News] {
+			seen[alert.News] = true
+			newsList = append(newsList, alert.News)
+		}
+	}
+	return n.Notify(newsList)
+}
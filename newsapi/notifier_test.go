@@ -0,0 +1,168 @@
+package newsapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+// sinkTestServer records the bodies posted to it, answering the statuses in order and then 200
+type sinkTestServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	paths    []string
+	bodies   []map[string]interface{}
+	headers  []http.Header
+	raw      [][]byte
+}
+
+func newSinkTestServer(t *testing.T, statuses ...int) *sinkTestServer {
+	s := &sinkTestServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if len(s.statuses) > 0 {
+			status := s.statuses[0]
+			s.statuses = s.statuses[1:]
+			w.WriteHeader(status)
+			return
+		}
+		var payload map[string]interface{}
+		json.Unmarshal(body, &payload)
+		s.paths = append(s.paths, r.URL.Path)
+		s.bodies = append(s.bodies, payload)
+		s.headers = append(s.headers, r.Header.Clone())
+		s.raw = append(s.raw, body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func notifierTestNews(n int) []*News {
+	newsList := make([]*News, n)
+	for i := range newsList {
+		newsList[i] = &News{
+			Title:      "Chips & <exports> rise",
+			Link:       "https://news.google.com/rss/articles/1",
+			SourceLink: "https://example.com/a|b>c",
+			Provider:   ProviderGoogle,
+		}
+	}
+	return newsList
+}
+
+func TestWebhookSink(t *testing.T) {
+	server := newSinkTestServer(t, http.StatusInternalServerError, http.StatusTooManyRequests)
+	sink := NewWebhookSink(server.URL, "secret", WithRetryDelay(0), WithBatchSize(2))
+	if err := sink.Send(notifierTestNews(3)); err != nil {
+		t.Fatal(err)
+	}
+	// the failed requests were retried, and the news sent in two batches
+	if len(server.bodies) != 2 {
+		t.Fatalf("got %d requests, want 2", len(server.bodies))
+	}
+	if news := server.bodies[0]["news"].([]interface{}); len(news) != 2 {
+		t.Errorf("first batch: got %d news, want 2", len(news))
+	}
+	for i, header := range server.headers {
+		if header.Get(SignatureHeader) != SignPayload("secret", server.raw[i]) {
+			t.Errorf("request %d: got signature %q", i, header.Get(SignatureHeader))
+		}
+	}
+
+	server = newSinkTestServer(t, http.StatusBadRequest)
+	sink = NewWebhookSink(server.URL, "", WithRetryDelay(0))
+	if err := sink.Send(notifierTestNews(1)); !errors.Is(err, ErrUnexpectedStatus) {
+		t.Errorf("client error: got %v, want ErrUnexpectedStatus", err)
+	}
+	if len(server.bodies) != 0 {
+		t.Errorf("client error: got %d retries, want none", len(server.bodies))
+	}
+}
+
+func TestSlackSink(t *testing.T) {
+	server := newSinkTestServer(t)
+	if err := NewSlackSink(server.URL).Send(notifierTestNews(2)); err != nil {
+		t.Fatal(err)
+	}
+	blocks := server.bodies[0]["blocks"].([]interface{})
+	if len(blocks) != 3 {
+		t.Fatalf("got %d blocks, want 2 sections and a divider", len(blocks))
+	}
+	text := blocks[0].(map[string]interface{})["text"].(map[string]interface{})["text"].(string)
+	want := "*<https://example.com/a%7Cb%3Ec|Chips &amp; &lt;exports&gt; rise>*"
+	if !strings.HasPrefix(text, want) {
+		t.Errorf("got %q, want the prefix %q", text, want)
+	}
+}
+
+func TestTelegramSink(t *testing.T) {
+	server := newSinkTestServer(t)
+	apiURL, _ := url.Parse(server.URL)
+	newsList := notifierTestNews(5)
+	newsList[0].Title = strings.Repeat("long title ", 360)
+	if err := NewTelegramSink("123:token", "42", WithTelegramAPIURL(apiURL), WithBatchSize(5)).Send(newsList); err != nil {
+		t.Fatal(err)
+	}
+	// the news do not fit in one message
+	if len(server.bodies) != 2 {
+		t.Fatalf("got %d messages, want 2", len(server.bodies))
+	}
+	for i, body := range server.bodies {
+		if server.paths[i] != "/bot123:token/sendMessage" || body["chat_id"] != "42" || body["parse_mode"] != "HTML" {
+			t.Errorf("message %d: got %s %v", i, server.paths[i], body)
+		}
+		if text := body["text"].(string); utf8.RuneCountInString(text) > telegramMaxMessageLength {
+			t.Errorf("message %d: got %d runes", i, utf8.RuneCountInString(text))
+		}
+	}
+	if !strings.Contains(server.bodies[1]["text"].(string), "Chips &amp; &lt;exports&gt; rise") {
+		t.Errorf("got %q", server.bodies[1]["text"])
+	}
+}
+
+func TestSinksRedactCredentials(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	apiURL, _ := url.Parse(server.URL)
+	server.Close()
+	for name, sink := range map[string]Sink{
+		"telegram": NewTelegramSink("123:secret-token", "42", WithTelegramAPIURL(apiURL), WithRetries(0)),
+		"webhook":  NewWebhookSink(server.URL+"/hooks/secret-token?key=secret-token", "", WithRetries(0)),
+		"slack":    NewSlackSink(strings.Replace(server.URL, "http://", "http://user:secret-token@", 1)+"/services/T0/B0/secret-token", WithRetries(0)),
+		"invalid":  NewWebhookSink("http://example.com/%zz/secret-token", "", WithRetries(0)),
+	} {
+		err := sink.Send(notifierTestNews(1))
+		if err == nil {
+			t.Fatalf("%s: got no error", name)
+		}
+		if strings.Contains(err.Error(), "secret-token") {
+			t.Errorf("%s: the error leaks the token: %v", name, err)
+		}
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	server := newSinkTestServer(t)
+	failing := newSinkTestServer(t, http.StatusForbidden)
+	notifier := NewNotifier(NewWebhookSink(server.URL, ""), NewSlackSink(failing.URL))
+	err := notifier.Notify(notifierTestNews(1))
+	var sinkErrs SinkErrors
+	if !errors.As(err, &sinkErrs) || len(sinkErrs) != 1 || sinkErrs[0].Sink != 1 {
+		t.Errorf("got %v, want the error of sink 1", err)
+	}
+	if len(server.bodies) != 1 {
+		t.Errorf("got %d requests to the working sink, want 1", len(server.bodies))
+	}
+	if err := notifier.Notify(nil); err != nil {
+		t.Errorf("no news: got %v", err)
+	}
+}
@@ -1,9 +1,18 @@
 package newsapi
 
 import (
+	"crypto/tls"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/gocolly/colly"
+)
+
+const (
+	defaultFetchConcurrency = 8
 )
 
 type QueryOption func(*newsApi)
@@ -20,6 +29,14 @@ func WithLocation(location string) QueryOption {
 	}
 }
 
+// WithEdition sets both the language and location of an edition, see LookupEdition and ParseEdition
+func WithEdition(edition Edition) QueryOption {
+	return func(n *newsApi) {
+		n.language = edition.Language
+		n.location = edition.Location
+	}
+}
+
 func WithLimit(limit int) QueryOption {
 	if limit > MaxSearchResults {
 		limit = MaxSearchResults
@@ -29,6 +46,13 @@ func WithLimit(limit int) QueryOption {
 	}
 }
 
+// WithSortOrder sets the order of the returned news, SortByPublishedDesc by default
+func WithSortOrder(order SortOrder) QueryOption {
+	return func(n *newsApi) {
+		n.sortOrder = order
+	}
+}
+
 func WithPeriod(period time.Duration) QueryOption {
 	return func(n *newsApi) {
 		n.period = &period
@@ -75,18 +99,282 @@ func WithoutDuration() QueryOption {
 
 type NewsApiOption func(*newsApi)
 
-func WithProxy(proxy *url.URL) NewsApiOption {
+// WithHTTPClient sets the client of the feed requests, http.DefaultClient by default.
+// The transport options below configure a copy of it, whatever their order.
+func WithHTTPClient(client *http.Client) NewsApiOption {
 	return func(n *newsApi) {
-		n.client = &http.Client{
-			Transport: &http.Transport{
-				Proxy: http.ProxyURL(proxy),
-			},
+		n.client = client
+		if len(n.tuning) > 0 {
+			client := n.copyClient()
+			client.Transport = tuneTransport(client.Transport, n.tuning...)
+			n.client = client
 		}
 	}
 }
 
+// WithTransport sets the transport of the feed requests, keeping the other settings of the client.
+// The transport options below configure a copy of it, whatever their order, when it is an *http.Transport.
+func WithTransport(transport http.RoundTripper) NewsApiOption {
+	return func(n *newsApi) {
+		client := n.copyClient()
+		client.Transport = tuneTransport(transport, n.tuning...)
+		n.client = client
+	}
+}
+
+// WithTimeout sets the time limit of a request, including reading the response body
+func WithTimeout(timeout time.Duration) NewsApiOption {
+	return func(n *newsApi) {
+		client := n.copyClient()
+		client.Timeout = timeout
+		n.client = client
+	}
+}
+
+// WithProxy sends the requests through the proxy.
+// Like the options below, it configures the *http.Transport of the client, other transports are left as is.
+func WithProxy(proxy *url.URL) NewsApiOption {
+	return func(n *newsApi) {
+		n.configureTransport(func(t *http.Transport) {
+			t.Proxy = http.ProxyURL(proxy)
+		})
+	}
+}
+
+// WithoutProxy connects directly, ignoring the proxy environment variables too
 func WithoutProxy() NewsApiOption {
 	return func(n *newsApi) {
-		n.client = nil
+		n.configureTransport(func(t *http.Transport) {
+			t.Proxy = nil
+		})
+	}
+}
+
+// WithProxyPool sends the requests of the api through the proxies of the pool, the source link and content requests too.
+// The transport options configure the transports of the pool, except WithProxy and WithoutProxy which do not apply.
+// Use WithFetchTransport with the package functions FetchSourceLinks and FetchSourceContents.
+func WithProxyPool(pool *ProxyPool) NewsApiOption {
+	return WithTransport(pool)
+}
+
+// WithTLSConfig sets the tls configuration, e.g. the root certificates of a corporate gateway
+func WithTLSConfig(config *tls.Config) NewsApiOption {
+	return func(n *newsApi) {
+		n.configureTransport(func(t *http.Transport) {
+			t.TLSClientConfig = config
+		})
+	}
+}
+
+// WithConnectionPool sets the maximum number of idle connections, of idle connections per host and of connections per host,
+// zero meaning no limit except for the idle connections per host which default to 2
+func WithConnectionPool(maxIdle, maxIdlePerHost, maxPerHost int) NewsApiOption {
+	return func(n *newsApi) {
+		n.configureTransport(func(t *http.Transport) {
+			t.MaxIdleConns = maxIdle
+			t.MaxIdleConnsPerHost = maxIdlePerHost
+			t.MaxConnsPerHost = maxPerHost
+		})
+	}
+}
+
+// WithIdleConnTimeout sets how long an idle connection is kept open, zero meaning forever
+func WithIdleConnTimeout(timeout time.Duration) NewsApiOption {
+	return func(n *newsApi) {
+		n.configureTransport(func(t *http.Transport) {
+			t.IdleConnTimeout = timeout
+		})
+	}
+}
+
+// WithHTTP2 enables or disables http/2, which is enabled by default
+func WithHTTP2(enabled bool) NewsApiOption {
+	return func(n *newsApi) {
+		n.configureTransport(func(t *http.Transport) {
+			t.ForceAttemptHTTP2 = enabled
+			if enabled {
+				t.TLSNextProto = nil
+			} else {
+				// a non-nil empty map disables http/2
+				t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+			}
+		})
+	}
+}
+
+// WithBaseURL sets the url of the google news endpoints, e.g. a mirror, a gateway or a newsapitest.Server.
+// The endpoints are relative to its path.
+func WithBaseURL(baseURL *url.URL) NewsApiOption {
+	return func(n *newsApi) {
+		if baseURL == nil {
+			n.baseURL = nil
+			return
+		}
+		u := *baseURL
+		n.baseURL = &u
+	}
+}
+
+// WithEndpoints overrides the paths of the feeds, see Endpoints.
+// The empty paths, and the topic and geo paths without their variable, keep their default.
+func WithEndpoints(endpoints Endpoints) NewsApiOption {
+	return func(n *newsApi) {
+		if endpoints.Top != "" {
+			n.endpoints.Top = endpoints.Top
+		}
+		if endpoints.Search != "" {
+			n.endpoints.Search = endpoints.Search
+		}
+		if strings.Contains(endpoints.Topic, topicVariable) {
+			n.endpoints.Topic = endpoints.Topic
+		}
+		if strings.Contains(endpoints.Geo, placeVariable) {
+			n.endpoints.Geo = endpoints.Geo
+		}
+	}
+}
+
+// WithHeaders sets the browser headers of the feed requests, the headers of a random profile by default
+func WithHeaders(headers *Headers) NewsApiOption {
+	return func(n *newsApi) {
+		n.headers = headers
+	}
+}
+
+// WithRecorder sends the feed requests through the recorder, use WithFetchTransport for the source link and content requests
+func WithRecorder(r *Recorder) NewsApiOption {
+	return func(n *newsApi) {
+		n.recorder = r
+	}
+}
+
+// WithWARC archives the feed requests to w
+func WithWARC(w *WARCWriter) NewsApiOption {
+	return func(n *newsApi) {
+		n.warc = w
+	}
+}
+
+// fetchConfig is the configuration of the source link and source content requests
+type fetchConfig struct {
+	transport http.RoundTripper
+	timeout   time.Duration
+	warc      *WARCWriter
+	headers   *Headers
+	language  string
+	// concurrency is the number of news fetched at the same time, and interval the minimal delay between two starts
+	concurrency int
+	interval    time.Duration
+}
+
+type FetchOption func(*fetchConfig)
+
+// WithFetchTransport sets the transport of the source link and source content requests, e.g. a WARCReplay
+func WithFetchTransport(transport http.RoundTripper) FetchOption {
+	return func(c *fetchConfig) {
+		c.transport = transport
+	}
+}
+
+// WithFetchClient sends the source link and source content requests with the transport and timeout of the client,
+// e.g. the client of an api returned by its HTTPClient method
+func WithFetchClient(client *http.Client) FetchOption {
+	return func(c *fetchConfig) {
+		c.transport = client.Transport
+		c.timeout = client.Timeout
+	}
+}
+
+// WithFetchConcurrency sets how many news are fetched at the same time, 8 by default
+func WithFetchConcurrency(concurrency int) FetchOption {
+	return func(c *fetchConfig) {
+		if concurrency > 0 {
+			c.concurrency = concurrency
+		}
+	}
+}
+
+// WithFetchInterval sets the minimal delay between the starts of two news fetches, none by default
+func WithFetchInterval(interval time.Duration) FetchOption {
+	return func(c *fetchConfig) {
+		c.interval = interval
+	}
+}
+
+// WithFetchTimeout sets the time limit of the source link and source content requests, 10 seconds by default
+func WithFetchTimeout(timeout time.Duration) FetchOption {
+	return func(c *fetchConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithFetchHeaders sets the browser headers of the source link and source content requests,
+// with the Accept-Language of the language tag, e.g. "pt-BR"
+func WithFetchHeaders(headers *Headers, language string) FetchOption {
+	return func(c *fetchConfig) {
+		c.headers = headers
+		c.language = language
+	}
+}
+
+// WithFetchWARC archives the source link and source content requests to w
+func WithFetchWARC(w *WARCWriter) FetchOption {
+	return func(c *fetchConfig) {
+		c.warc = w
+	}
+}
+
+func newFetchConfig(options []FetchOption) *fetchConfig {
+	c := &fetchConfig{concurrency: defaultFetchConcurrency}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// each runs fetch for every news, throttled by the concurrency and interval of the config
+func (c *fetchConfig) each(newsList []*News, fetch func(news *News)) {
+	var throttle <-chan time.Time
+	if c.interval > 0 {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+	sem := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+	for _, news := range newsList {
+		wg.Add(1)
+		go func(news *News) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if throttle != nil {
+				<-throttle
+			}
+			fetch(news)
+		}(news)
+	}
+	wg.Wait()
+}
+
+// newCollector returns an async collector using the transport of the config, setting the browser headers
+func (c *fetchConfig) newCollector() *colly.Collector {
+	collector := colly.NewCollector(colly.Async(true))
+	transport := c.transport
+	headers, language := c.headers, c.language
+	// the headers of an api client, see WithFetchClient, unless others are set
+	if t, ok := transport.(*headersTransport); ok {
+		transport = t.transport
+		if headers == nil {
+			headers, language = t.headers, t.language
+		}
+	}
+	if c.warc != nil {
+		transport = NewWARCTransport(transport, c.warc)
+	}
+	collector.WithTransport(NewHeadersTransport(transport, headers, language))
+	if c.timeout > 0 {
+		collector.SetRequestTimeout(c.timeout)
 	}
+	return collector
 }
@@ -0,0 +1,44 @@
+package newsapi
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestTransportOptionsOrder(t *testing.T) {
+	proxyURL, _ := url.Parse("http://proxy.example.com:8080")
+	tlsConfig := &tls.Config{ServerName: "example.com"}
+	client := &http.Client{Transport: &http.Transport{}, Timeout: time.Second}
+	for name, options := range map[string][]NewsApiOption{
+		"transport last":   {WithProxy(proxyURL), WithTLSConfig(tlsConfig), WithTransport(&http.Transport{})},
+		"transport first":  {WithTransport(&http.Transport{}), WithProxy(proxyURL), WithTLSConfig(tlsConfig)},
+		"client last":      {WithProxy(proxyURL), WithTLSConfig(tlsConfig), WithHTTPClient(client)},
+		"default client":   {WithProxy(proxyURL), WithTLSConfig(tlsConfig)},
+		"timeout in first": {WithTimeout(time.Second), WithTLSConfig(tlsConfig), WithProxy(proxyURL)},
+	} {
+		api := NewNewsApi(options...)
+		transport, ok := api.client.Transport.(*http.Transport)
+		if !ok {
+			t.Fatalf("%s: got transport %T", name, api.client.Transport)
+		}
+		req, _ := http.NewRequest(http.MethodGet, "https://news.google.com/rss", nil)
+		if transport.Proxy == nil {
+			t.Errorf("%s: got no proxy", name)
+		} else if proxy, _ := transport.Proxy(req); proxy == nil || proxy.Host != proxyURL.Host {
+			t.Errorf("%s: got proxy %v", name, proxy)
+		}
+		if transport.TLSClientConfig == nil || transport.TLSClientConfig.ServerName != tlsConfig.ServerName {
+			t.Errorf("%s: got tls config %v", name, transport.TLSClientConfig)
+		}
+	}
+	// the injected client is not changed
+	if client.Transport.(*http.Transport).Proxy != nil {
+		t.Error("the injected client was configured")
+	}
+	if config := http.DefaultTransport.(*http.Transport).TLSClientConfig; config != nil && config.ServerName == tlsConfig.ServerName {
+		t.Error("the default transport was configured")
+	}
+}
@@ -0,0 +1,201 @@
+package newsapi
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// PlaceKind is the kind of geographic area a Place refers to
+type PlaceKind int
+
+const (
+	// PlaceCountry is a country identified by its ISO 3166-1 alpha-2 code
+	PlaceCountry PlaceKind = iota
+	// PlaceRegion is a free-text area such as a state, province or county
+	PlaceRegion
+	// PlaceCity is a city or town
+	PlaceCity
+)
+
+const (
+	// maxPlaceNameLength is the longest place name accepted by Validate
+	maxPlaceNameLength = 100
+)
+
+// Place is a geographic area accepted by the google news geo section
+type Place struct {
+	Name    string
+	Kind    PlaceKind
+	Country string
+}
+
+// Country returns a place for the country code, e.g. LocationTaiwan
+func Country(code string) Place {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return Place{Name: code, Kind: PlaceCountry, Country: code}
+}
+
+// Region returns a place for a region name, country is optional
+func Region(name, country string) Place {
+	return Place{
+		Name:    strings.TrimSpace(name),
+		Kind:    PlaceRegion,
+		Country: strings.ToUpper(strings.TrimSpace(country)),
+	}
+}
+
+// City returns a place for a city name, country is optional
+func City(name, country string) Place {
+	return Place{
+		Name:    strings.TrimSpace(name),
+		Kind:    PlaceCity,
+		Country: strings.ToUpper(strings.TrimSpace(country)),
+	}
+}
+
+// ParsePlace parses a free-text location.
+// Two-letter codes in any case are treated as countries, names found in Cities as cities
+// and anything else as a region.
+func ParsePlace(location string) Place {
+	location = strings.TrimSpace(location)
+	if isCountryCode(strings.ToUpper(location)) {
+		return Country(location)
+	}
+	if p, ok := LookupCity(location); ok {
+		return p
+	}
+	return Region(location, "")
+}
+
+// LookupCity looks up a city in Cities, ignoring case
+func LookupCity(name string) (Place, bool) {
+	name = strings.TrimSpace(name)
+	if p, ok := Cities[name]; ok {
+		return p, true
+	}
+	// Cities may be changed at any time, so it is not indexed
+	for cityName, p := range Cities {
+		if strings.EqualFold(cityName, name) {
+			return p, true
+		}
+	}
+	return Place{}, false
+}
+
+// Validate checks whether the place can be used in a geo section request
+func (p Place) Validate() error {
+	if p.Name == "" {
+		return ErrEmptyLocation
+	}
+	if len(p.Name) > maxPlaceNameLength {
+		return ErrInvalidPlace
+	}
+	for _, r := range p.Name {
+		if unicode.IsControl(r) {
+			return ErrInvalidPlace
+		}
+	}
+	if p.Kind == PlaceCountry && !isCountryCode(p.Name) {
+		return ErrInvalidPlace
+	}
+	if p.Country != "" && !isCountryCode(p.Country) {
+		return ErrInvalidPlace
+	}
+	return nil
+}
+
+// PathSegment returns the place name escaped for use in a url path
+func (p Place) PathSegment() string {
+	return url.PathEscape(p.Name)
+}
+
+// String returns the place as "Name, Country" when the country is known
+func (p Place) String() string {
+	if p.Kind == PlaceCountry || p.Country == "" {
+		return p.Name
+	}
+	return p.Name + ", " + p.Country
+}
+
+// isCountryCode checks whether s looks like an ISO 3166-1 alpha-2 code
+func isCountryCode(s string) bool {
+	if len(s) != 2 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	// Cities is a small gazetteer of major cities keyed by their english name
+	Cities = map[string]Place{
+		// Africa
+		"Cairo":        City("Cairo", LocationEgypt),
+		"Cape Town":    City("Cape Town", LocationSouthAfrica),
+		"Johannesburg": City("Johannesburg", LocationSouthAfrica),
+		"Lagos":        City("Lagos", LocationNigeria),
+		"Nairobi":      City("Nairobi", LocationKenya),
+
+		// Asia
+		"Bangkok":   City("Bangkok", LocationThailand),
+		"Beijing":   City("Beijing", LocationChina),
+		"Delhi":     City("Delhi", LocationIndia),
+		"Dubai":     City("Dubai", LocationUnitedArabEmirates),
+		"Hong Kong": City("Hong Kong", LocationHongKong),
+		"Jakarta":   City("Jakarta", LocationIndonesia),
+		"Kaohsiung": City("Kaohsiung", LocationTaiwan),
+		"Manila":    City("Manila", LocationPhilippines),
+		"Mumbai":    City("Mumbai", LocationIndia),
+		"Osaka":     City("Osaka", LocationJapan),
+		"Seoul":     City("Seoul", LocationRepublicOfKorea),
+		"Shanghai":  City("Shanghai", LocationChina),
+		"Singapore": City("Singapore", LocationSingapore),
+		"Taichung":  City("Taichung", LocationTaiwan),
+		"Taipei":    City("Taipei", LocationTaiwan),
+		"Tel Aviv":  City("Tel Aviv", LocationIsrael),
+		"Tokyo":     City("Tokyo", LocationJapan),
+
+		// Europe
+		"Amsterdam": City("Amsterdam", LocationNetherlands),
+		"Berlin":    City("Berlin", LocationGermany),
+		"Madrid":    City("Madrid", LocationSpain),
+		"Istanbul":  City("Istanbul", LocationTurkey),
+		"London":    City("London", LocationUnitedKingdom),
+		"Milan":     City("Milan", LocationItaly),
+		"Moscow":    City("Moscow", LocationRussia),
+		"Paris":     City("Paris", LocationFrance),
+		"Rome":      City("Rome", LocationItaly),
+		"Vienna":    City("Vienna", LocationAustria),
+		"Warsaw":    City("Warsaw", LocationPoland),
+		"Zurich":    City("Zurich", LocationSwitzerland),
+
+		// North America
+		"Chicago":       City("Chicago", LocationUnitedStates),
+		"Los Angeles":   City("Los Angeles", LocationUnitedStates),
+		"Mexico City":   City("Mexico City", LocationMexico),
+		"Montreal":      City("Montreal", LocationCanada),
+		"New York":      City("New York", LocationUnitedStates),
+		"San Francisco": City("San Francisco", LocationUnitedStates),
+		"Seattle":       City("Seattle", LocationUnitedStates),
+		"Toronto":       City("Toronto", LocationCanada),
+		"Vancouver":     City("Vancouver", LocationCanada),
+		"Washington":    City("Washington", LocationUnitedStates),
+
+		// South America
+		"Bogota":       City("Bogota", LocationColombia),
+		"Buenos Aires": City("Buenos Aires", LocationArgentina),
+		"Lima":         City("Lima", LocationPeru),
+		"Santiago":     City("Santiago", LocationChile),
+		"Sao Paulo":    City("Sao Paulo", LocationBrazil),
+
+		// Oceania
+		"Auckland":  City("Auckland", LocationNewZealand),
+		"Melbourne": City("Melbourne", LocationAustralia),
+		"Sydney":    City("Sydney", LocationAustralia),
+	}
+)
@@ -0,0 +1,76 @@
+package newsapi
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParsePlace(t *testing.T) {
+	tests := []struct {
+		location string
+		want     Place
+	}{
+		{"TW", Place{Name: "TW", Kind: PlaceCountry, Country: "TW"}},
+		{" us ", Place{Name: "US", Kind: PlaceCountry, Country: "US"}},
+		{"Taipei", Place{Name: "Taipei", Kind: PlaceCity, Country: "TW"}},
+		{"new york", Place{Name: "New York", Kind: PlaceCity, Country: "US"}},
+		{"  SAO PAULO", Place{Name: "Sao Paulo", Kind: PlaceCity, Country: "BR"}},
+		// unknown cities are regions
+		{"Hsinchu", Place{Name: "Hsinchu", Kind: PlaceRegion}},
+		{"California", Place{Name: "California", Kind: PlaceRegion}},
+		{"USA", Place{Name: "USA", Kind: PlaceRegion}},
+	}
+	for _, tt := range tests {
+		if got := ParsePlace(tt.location); got != tt.want {
+			t.Errorf("ParsePlace(%q): got %+v, want %+v", tt.location, got, tt.want)
+		}
+	}
+}
+
+func TestLookupCity(t *testing.T) {
+	for _, name := range []string{"Tokyo", "tokyo", "TOKYO", " Tokyo "} {
+		if p, ok := LookupCity(name); !ok || p.Name != "Tokyo" || p.Country != LocationJapan {
+			t.Errorf("LookupCity(%q): got %+v, %v", name, p, ok)
+		}
+	}
+	if _, ok := LookupCity("Atlantis"); ok {
+		t.Error("LookupCity(Atlantis): got a city")
+	}
+}
+
+func TestPlaceValidate(t *testing.T) {
+	tests := []struct {
+		place Place
+		want  error
+	}{
+		{Country("tw"), nil},
+		{City("Taipei", "tw"), nil},
+		{Region("Bavaria", ""), nil},
+		{Region("", "DE"), ErrEmptyLocation},
+		{Country("TWN"), ErrInvalidPlace},
+		{Country("1A"), ErrInvalidPlace},
+		{City("Taipei", "Taiwan"), ErrInvalidPlace},
+		// the name is trimmed
+		{Region("Bavaria\n", ""), nil},
+		{Place{Name: "Bava\x00ria", Kind: PlaceRegion}, ErrInvalidPlace},
+		{Region(strings.Repeat("a", maxPlaceNameLength+1), ""), ErrInvalidPlace},
+	}
+	for _, tt := range tests {
+		if err := tt.place.Validate(); !errors.Is(err, tt.want) {
+			t.Errorf("Validate(%+v): got %v, want %v", tt.place, err, tt.want)
+		}
+	}
+}
+
+func TestPlaceString(t *testing.T) {
+	if s := City("San Francisco", "us").String(); s != "San Francisco, US" {
+		t.Errorf("got %q", s)
+	}
+	if s := Country("tw").String(); s != "TW" {
+		t.Errorf("got %q", s)
+	}
+	if s := City("San Francisco", "").PathSegment(); s != "San%20Francisco" {
+		t.Errorf("got path segment %q", s)
+	}
+}
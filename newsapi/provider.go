@@ -0,0 +1,248 @@
+package newsapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	ProviderGoogle = "google"
+	ProviderBing   = "bing"
+	ProviderFeed   = "feed"
+	ProviderGDELT  = "gdelt"
+)
+
+var (
+	_ Provider = (*newsApi)(nil)
+	_ Provider = (*combinedProvider)(nil)
+)
+
+// providerConfig is the configuration shared by the providers other than google news
+type providerConfig struct {
+	baseURL  url.URL
+	client   *http.Client
+	headers  *Headers
+	language string
+	location string
+	limit    int
+}
+
+type ProviderOption func(*providerConfig)
+
+// WithProviderBaseURL sets the base url of the provider, e.g. a local httptest server
+func WithProviderBaseURL(baseURL *url.URL) ProviderOption {
+	return func(c *providerConfig) {
+		c.baseURL = *baseURL
+	}
+}
+
+// WithProviderClient sets the http client of the provider
+func WithProviderClient(client *http.Client) ProviderOption {
+	return func(c *providerConfig) {
+		c.client = client
+	}
+}
+
+// WithProviderHeaders sets the browser headers of the provider, the headers of a random profile by default
+func WithProviderHeaders(headers *Headers) ProviderOption {
+	return func(c *providerConfig) {
+		c.headers = headers
+	}
+}
+
+// WithProviderEdition sets the language and location of the provider
+func WithProviderEdition(edition Edition) ProviderOption {
+	return func(c *providerConfig) {
+		c.language = edition.Language
+		c.location = edition.Location
+	}
+}
+
+// WithProviderLimit sets the maximal number of news returned by the provider
+func WithProviderLimit(limit int) ProviderOption {
+	if limit > MaxSearchResults {
+		limit = MaxSearchResults
+	}
+	return func(c *providerConfig) {
+		c.limit = limit
+	}
+}
+
+func newProviderConfig(baseURL url.URL, options []ProviderOption) *providerConfig {
+	c := &providerConfig{
+		baseURL:  baseURL,
+		client:   http.DefaultClient,
+		language: defaultNewsApi.language,
+		location: defaultNewsApi.location,
+		limit:    defaultNewsApi.limit,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	if c.headers == nil {
+		c.headers = NewHeaders()
+	}
+	return c
+}
+
+// get sends a get request to the path and query of the base url
+func (c *providerConfig) get(path string, query url.Values) (*http.Response, error) {
+	reqURL := c.baseURL
+	reqURL.Path = strings.TrimSuffix(reqURL.Path, "/") + "/" + strings.TrimPrefix(path, "/")
+	reqURL.RawQuery = query.Encode()
+	req, err := http.NewRequest(http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	c.headers.Apply(req, editionLanguage(c.language, c.location))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+	}
+	return resp, nil
+}
+
+// finish sorts the news and applies the limit
+func (c *providerConfig) finish(newsList []*News) []*News {
+	SortNews(newsList, SortByPublishedDesc)
+	if c.limit > 0 && c.limit < len(newsList) {
+		newsList = newsList[:c.limit]
+	}
+	return newsList
+}
+
+// normalizeTopic validates the topic and returns it in upper case
+func normalizeTopic(topic string) (string, error) {
+	if topic == "" {
+		return "", ErrEmptyTopic
+	}
+	topic = strings.ToUpper(topic)
+	if _, ok := TopicMap[topic]; !ok {
+		return "", ErrInvalidTopic
+	}
+	return topic, nil
+}
+
+// combinedProvider queries several providers and merges their news
+type combinedProvider struct {
+	providers []Provider
+	limit     int
+}
+
+// NewCombinedProvider returns a provider querying every provider concurrently and merging the results.
+// When only some providers fail, the merged news is returned along with a ProviderErrors.
+func NewCombinedProvider(providers ...Provider) *combinedProvider {
+	return &combinedProvider{
+		providers: providers,
+		limit:     defaultNewsApi.limit,
+	}
+}
+
+// ProviderError is the error of a single provider in a combined provider
+type ProviderError struct {
+	Provider string
+	Err      error
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("provider %s: %s", e.Provider, e.Err)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// ProviderErrors collects the errors of the failed providers in a combined provider
+type ProviderErrors []*ProviderError
+
+func (e ProviderErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d provider(s) failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// SetLimit sets the maximal number of merged news, 0 means no limit
+func (p *combinedProvider) SetLimit(limit int) {
+	p.limit = limit
+}
+
+func (p *combinedProvider) Name() string {
+	names := make([]string, 0, len(p.providers))
+	for _, provider := range p.providers {
+		names = append(names, provider.Name())
+	}
+	return strings.Join(names, "+")
+}
+
+func (p *combinedProvider) GetTopNews() ([]*News, error) {
+	return p.query(Provider.GetTopNews)
+}
+
+func (p *combinedProvider) GetTopicNews(topic string) ([]*News, error) {
+	return p.query(func(provider Provider) ([]*News, error) {
+		return provider.GetTopicNews(topic)
+	})
+}
+
+func (p *combinedProvider) SearchNews(query string) ([]*News, error) {
+	return p.query(func(provider Provider) ([]*News, error) {
+		return provider.SearchNews(query)
+	})
+}
+
+// query runs fetch on every provider, providers not supporting the query are skipped
+func (p *combinedProvider) query(fetch func(provider Provider) ([]*News, error)) ([]*News, error) {
+	results := make([][]*News, len(p.providers))
+	errs := make([]error, len(p.providers))
+	var wg sync.WaitGroup
+	for i, provider := range p.providers {
+		wg.Add(1)
+		go func(i int, provider Provider) {
+			defer wg.Done()
+			results[i], errs[i] = fetch(provider)
+		}(i, provider)
+	}
+	wg.Wait()
+
+	var providerErrs ProviderErrors
+	var newsList []*News
+	supported := 0
+	for i, provider := range p.providers {
+		if errors.Is(errs[i], ErrNotSupported) {
+			continue
+		}
+		supported++
+		if errs[i] != nil {
+			providerErrs = append(providerErrs, &ProviderError{Provider: provider.Name(), Err: errs[i]})
+			continue
+		}
+		newsList = append(newsList, results[i]...)
+	}
+	if supported == 0 {
+		return nil, ErrNotSupported
+	}
+	if len(providerErrs) == supported {
+		return nil, providerErrs
+	}
+
+	newsList = MergeNews(newsList)
+	SortNews(newsList, SortByPublishedDesc)
+	if p.limit > 0 && p.limit < len(newsList) {
+		newsList = newsList[:p.limit]
+	}
+	if len(providerErrs) > 0 {
+		return newsList, providerErrs
+	}
+	return newsList, nil
+}
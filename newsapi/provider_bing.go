@@ -0,0 +1,125 @@
+package newsapi
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/mmcdole/gofeed"
+)
+
+var (
+	_ Provider = (*bingProvider)(nil)
+
+	bingNewsURL = url.URL{
+		Scheme: "https",
+		Host:   "www.bing.com",
+		Path:   "/",
+	}
+
+	// TopicBingCategory maps topics to bing news categories
+	TopicBingCategory = map[string]string{
+		TopicWorld:         "World",
+		TopicNation:        "US",
+		TopicBusiness:      "Business",
+		TopicTechnology:    "ScienceAndTechnology",
+		TopicEntertainment: "Entertainment",
+		TopicSports:        "Sports",
+		TopicScience:       "ScienceAndTechnology",
+		TopicHealth:        "Health",
+	}
+)
+
+// bingProvider gets the news from the bing news rss feeds
+type bingProvider struct {
+	*providerConfig
+}
+
+// NewBingProvider returns a provider for bing news
+func NewBingProvider(options ...ProviderOption) *bingProvider {
+	return &bingProvider{
+		providerConfig: newProviderConfig(bingNewsURL, options),
+	}
+}
+
+func (p *bingProvider) Name() string {
+	return ProviderBing
+}
+
+// GetTopNews gets the top news
+func (p *bingProvider) GetTopNews() ([]*News, error) {
+	return p.getNews("news", url.Values{})
+}
+
+// GetTopicNews gets the news by topic
+func (p *bingProvider) GetTopicNews(topic string) ([]*News, error) {
+	topic, err := normalizeTopic(topic)
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	q.Set("category", TopicBingCategory[topic])
+	newsList, err := p.getNews("news", q)
+	if err != nil {
+		return nil, err
+	}
+	tagTopic(newsList, topic)
+	return newsList, nil
+}
+
+// SearchNews searches the news by query
+func (p *bingProvider) SearchNews(query string) ([]*News, error) {
+	if query == "" {
+		return nil, ErrEmptyQuery
+	}
+	q := url.Values{}
+	q.Set("q", query)
+	return p.getNews("news/search", q)
+}
+
+// market returns the bing market of the configured language and location, e.g. "en-US"
+func (p *bingProvider) market() string {
+	language, _, _ := splitLanguage(p.language)
+	return language + "-" + p.location
+}
+
+func (p *bingProvider) getNews(path string, q url.Values) ([]*News, error) {
+	q.Set("format", "rss")
+	q.Set("mkt", p.market())
+	resp, err := p.get(path, q)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	parser := gofeed.NewParser()
+	feed, err := parser.ParseString(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing response body: %w", err)
+	}
+
+	newsList := make([]*News, 0, len(feed.Items))
+	for i, item := range feed.Items {
+		news := NewNews(item)
+		news.Provider = ProviderBing
+		news.feedRank = i
+		// bing links redirect through apiclick.aspx with the source link in the url parameter
+		if linkURL, err := url.Parse(news.Link); err == nil {
+			if source := linkURL.Query().Get("url"); source != "" {
+				news.SourceLink = source
+			}
+		}
+		if sources := item.Extensions["news"]["Source"]; len(sources) > 0 {
+			news.SourceSiteName = sources[0].Value
+		}
+		if images := item.Extensions["news"]["Image"]; len(images) > 0 && news.ImageURL == "" {
+			news.ImageURL = images[0].Value
+		}
+		newsList = append(newsList, news)
+	}
+	return p.finish(newsList), nil
+}
@@ -0,0 +1,138 @@
+package newsapi
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+var _ Provider = (*feedProvider)(nil)
+
+// feedProvider gets the news from rss, atom or json feed urls
+type feedProvider struct {
+	*providerConfig
+	feedURLs   []string
+	topicFeeds map[string][]string
+	// api reads the feeds, see newsApi.GetFeedNews
+	api *newsApi
+}
+
+// NewFeedProvider returns a provider for the feed urls.
+// GetTopNews reads every feed, SearchNews filters them by query and GetTopicNews reads the feeds added by AddTopicFeeds.
+func NewFeedProvider(feedURLs []string, options ...ProviderOption) *feedProvider {
+	p := &feedProvider{
+		providerConfig: newProviderConfig(url.URL{}, options),
+		feedURLs:       feedURLs,
+		topicFeeds:     map[string][]string{},
+	}
+	api := *defaultNewsApi
+	api.client = p.client
+	api.headers = p.headers
+	api.language = p.language
+	api.location = p.location
+	// the limit applies to the merged feeds
+	api.limit = 0
+	p.api = &api
+	return p
+}
+
+// AddTopicFeeds adds the feed urls of a topic
+func (p *feedProvider) AddTopicFeeds(topic string, feedURLs ...string) error {
+	topic, err := normalizeTopic(topic)
+	if err != nil {
+		return err
+	}
+	p.topicFeeds[topic] = append(p.topicFeeds[topic], feedURLs...)
+	return nil
+}
+
+func (p *feedProvider) Name() string {
+	return ProviderFeed
+}
+
+// GetTopNews gets the news of every feed
+func (p *feedProvider) GetTopNews() ([]*News, error) {
+	newsList, err := p.fetchFeeds(p.feedURLs)
+	if err != nil {
+		return nil, err
+	}
+	return p.finish(newsList), nil
+}
+
+// GetTopicNews gets the news of the feeds of the topic
+func (p *feedProvider) GetTopicNews(topic string) ([]*News, error) {
+	topic, err := normalizeTopic(topic)
+	if err != nil {
+		return nil, err
+	}
+	feedURLs, ok := p.topicFeeds[topic]
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	newsList, err := p.fetchFeeds(feedURLs)
+	if err != nil {
+		return nil, err
+	}
+	tagTopic(newsList, topic)
+	return p.finish(newsList), nil
+}
+
+// SearchNews gets the news of every feed containing all words of the query in the title or description
+func (p *feedProvider) SearchNews(query string) ([]*News, error) {
+	if query == "" {
+		return nil, ErrEmptyQuery
+	}
+	newsList, err := p.fetchFeeds(p.feedURLs)
+	if err != nil {
+		return nil, err
+	}
+	words := strings.Fields(strings.ToLower(query))
+	matched := make([]*News, 0, len(newsList))
+	for _, news := range newsList {
+		if containsWords(strings.ToLower(news.Title+" "+news.Description), words) {
+			matched = append(matched, news)
+		}
+	}
+	return p.finish(matched), nil
+}
+
+// fetchFeeds fetches the feeds concurrently, it fails only if every feed fails
+func (p *feedProvider) fetchFeeds(feedURLs []string) ([]*News, error) {
+	if len(feedURLs) == 0 {
+		return nil, ErrEmptyLink
+	}
+	results := make([][]*News, len(feedURLs))
+	errs := make([]error, len(feedURLs))
+	var wg sync.WaitGroup
+	for i, feedURL := range feedURLs {
+		wg.Add(1)
+		go func(i int, feedURL string) {
+			defer wg.Done()
+			results[i], errs[i] = p.fetchFeed(feedURL)
+		}(i, feedURL)
+	}
+	wg.Wait()
+
+	var newsList []*News
+	failed := 0
+	for i := range feedURLs {
+		if errs[i] != nil {
+			failed++
+			continue
+		}
+		newsList = append(newsList, results[i]...)
+	}
+	if failed == len(feedURLs) {
+		return nil, errs[0]
+	}
+	return MergeNews(newsList), nil
+}
+
+func (p *feedProvider) fetchFeed(feedURL string) ([]*News, error) {
+	newsList, err := p.api.GetFeedNews(feedURL)
+	if err != nil {
+		return nil, fmt.Errorf("error getting feed %s: %w", feedURL, err)
+	}
+	return newsList, nil
+}
@@ -0,0 +1,114 @@
+package newsapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var (
+	_ Provider = (*gdeltProvider)(nil)
+
+	gdeltURL = url.URL{
+		Scheme: "https",
+		Host:   "api.gdeltproject.org",
+		Path:   "/api/v2/",
+	}
+)
+
+const (
+	gdeltDateLayout = "20060102T150405Z"
+)
+
+// gdeltArticle is an article of the gdelt doc api in ArtList mode
+type gdeltArticle struct {
+	URL           string `json:"url"`
+	URLMobile     string `json:"url_mobile"`
+	Title         string `json:"title"`
+	SeenDate      string `json:"seendate"`
+	SocialImage   string `json:"socialimage"`
+	Domain        string `json:"domain"`
+	Language      string `json:"language"`
+	SourceCountry string `json:"sourcecountry"`
+}
+
+type gdeltResponse struct {
+	Articles []gdeltArticle `json:"articles"`
+}
+
+// gdeltProvider searches the news with the gdelt doc api, it only supports SearchNews
+type gdeltProvider struct {
+	*providerConfig
+}
+
+// NewGDELTProvider returns a provider for the gdelt doc api
+func NewGDELTProvider(options ...ProviderOption) *gdeltProvider {
+	return &gdeltProvider{
+		providerConfig: newProviderConfig(gdeltURL, options),
+	}
+}
+
+func (p *gdeltProvider) Name() string {
+	return ProviderGDELT
+}
+
+// GetTopNews is not supported by gdelt
+func (p *gdeltProvider) GetTopNews() ([]*News, error) {
+	return nil, ErrNotSupported
+}
+
+// GetTopicNews is not supported by gdelt
+func (p *gdeltProvider) GetTopicNews(topic string) ([]*News, error) {
+	return nil, ErrNotSupported
+}
+
+// SearchNews searches the news by query, see the gdelt doc api for the query syntax
+func (p *gdeltProvider) SearchNews(query string) ([]*News, error) {
+	if query == "" {
+		return nil, ErrEmptyQuery
+	}
+	q := url.Values{}
+	q.Set("query", query)
+	q.Set("mode", "ArtList")
+	q.Set("format", "json")
+	q.Set("sort", "DateDesc")
+	if p.limit > 0 {
+		q.Set("maxrecords", strconv.Itoa(p.limit))
+	}
+	resp, err := p.get("doc/doc", q)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result gdeltResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error parsing response body: %w", err)
+	}
+
+	newsList := make([]*News, 0, len(result.Articles))
+	for i, article := range result.Articles {
+		news := &News{
+			Title:          article.Title,
+			Link:           article.URL,
+			GUID:           article.URL,
+			Published:      article.SeenDate,
+			ImageURL:       article.SocialImage,
+			SourceLink:     article.URL,
+			SourceImageURL: article.SocialImage,
+			SourceSiteName: article.Domain,
+			Provider:       ProviderGDELT,
+			feedRank:       i,
+		}
+		if article.URLMobile != "" {
+			news.Links = []string{article.URL, article.URLMobile}
+		}
+		if t, err := time.Parse(gdeltDateLayout, article.SeenDate); err == nil {
+			news.PublishedParsed = &t
+		}
+		newsList = append(newsList, news)
+	}
+	return p.finish(newsList), nil
+}
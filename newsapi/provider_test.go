@@ -0,0 +1,200 @@
+package newsapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zhima-Mochi/newsApi-go/newsapi/newsapitest"
+)
+
+const bingTestFeed = `<?xml version="1.0" encoding="utf-8"?>
+<rss version="2.0" xmlns:News="https://www.bing.com/news/search?q=test&amp;format=rss">
+<channel>
+<title>Bing News</title>
+<item>
+<title>Chip exports rise</title>
+<link>http://www.bing.com/news/apiclick.aspx?ref=FexRss&amp;url=https%3a%2f%2fwww.reuters.com%2fchips&amp;c=1</link>
+<description>Exports of chips rose.</description>
+<pubDate>{published}</pubDate>
+<News:Source>Reuters</News:Source>
+<News:Image>https://www.bing.com/th?id=chips</News:Image>
+</item>
+</channel>
+</rss>`
+
+const gdeltTestResponse = `{"articles": [
+{"url": "https://apnews.com/markets", "title": "Markets close higher", "seendate": "%s", "domain": "apnews.com", "language": "English"},
+{"url": "https://www.reuters.com/chips", "title": "Chip exports rise", "seendate": "%s", "domain": "reuters.com", "language": "English"}
+]}`
+
+func newProviderTestServer(t *testing.T) *httptest.Server {
+	now := time.Now().UTC()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/news", "/news/search":
+			if r.URL.Query().Get("format") != "rss" || r.URL.Query().Get("mkt") != "en-US" {
+				http.Error(w, "bad query", http.StatusBadRequest)
+				return
+			}
+			io.WriteString(w, strings.Replace(bingTestFeed, "{published}", now.Format(time.RFC1123Z), 1))
+		case "/doc/doc":
+			if r.URL.Query().Get("query") == "" || r.URL.Query().Get("format") != "json" {
+				http.Error(w, "bad query", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintf(w, gdeltTestResponse, now.Add(-time.Hour).Format(gdeltDateLayout), now.Add(-2*time.Hour).Format(gdeltDateLayout))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestBingProvider(t *testing.T) {
+	server := newProviderTestServer(t)
+	baseURL, _ := url.Parse(server.URL)
+	p := NewBingProvider(WithProviderBaseURL(baseURL))
+
+	for name, fetch := range map[string]func() ([]*News, error){
+		"top":    p.GetTopNews,
+		"topic":  func() ([]*News, error) { return p.GetTopicNews(TopicBusiness) },
+		"search": func() ([]*News, error) { return p.SearchNews("chips") },
+	} {
+		newsList, err := fetch()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(newsList) != 1 {
+			t.Fatalf("%s: got %d news, want 1", name, len(newsList))
+		}
+		news := newsList[0]
+		if news.Provider != ProviderBing || news.SourceLink != "https://www.reuters.com/chips" || news.Publisher() != "Reuters" {
+			t.Errorf("%s: got %+v", name, news)
+		}
+	}
+	if _, err := p.GetTopicNews("nope"); !errors.Is(err, ErrInvalidTopic) {
+		t.Errorf("invalid topic: got %v, want ErrInvalidTopic", err)
+	}
+}
+
+func TestGDELTProvider(t *testing.T) {
+	server := newProviderTestServer(t)
+	baseURL, _ := url.Parse(server.URL)
+	p := NewGDELTProvider(WithProviderBaseURL(baseURL))
+
+	newsList, err := p.SearchNews("chips")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newsList) != 2 || newsList[0].Title != "Markets close higher" || newsList[0].PublishedParsed == nil {
+		t.Fatalf("got %+v", newsList)
+	}
+	if newsList[1].SourceLink != "https://www.reuters.com/chips" || newsList[1].Publisher() != "reuters.com" {
+		t.Errorf("got %+v", newsList[1])
+	}
+	if _, err := p.GetTopNews(); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("top: got %v, want ErrNotSupported", err)
+	}
+}
+
+func TestFeedProvider(t *testing.T) {
+	server := newsapitest.NewServer()
+	defer server.Close()
+	articles := testArticles(server)
+	server.SetTopNews(newsapitest.Fixture{Articles: articles[:1]})
+	server.SetTopicNews("BUSINESS", newsapitest.Fixture{Articles: articles[1:]})
+
+	p := NewFeedProvider([]string{server.URL + "/rss", server.URL + "/missing"})
+	if err := p.AddTopicFeeds(TopicBusiness, server.URL+"/rss/headlines/section/topic/BUSINESS"); err != nil {
+		t.Fatal(err)
+	}
+	newsList, err := p.GetTopNews()
+	if err != nil {
+		t.Fatalf("top: %v", err)
+	}
+	if len(newsList) != 1 || newsList[0].Provider != ProviderFeed {
+		t.Fatalf("top: got %+v", newsList)
+	}
+	if newsList, err := p.SearchNews("CHIP exports"); err != nil || len(newsList) != 1 {
+		t.Errorf("search: got %d news, %v", len(newsList), err)
+	}
+	if newsList, err := p.SearchNews("weather"); err != nil || len(newsList) != 0 {
+		t.Errorf("search: got %d news, %v", len(newsList), err)
+	}
+	newsList, err = p.GetTopicNews(TopicBusiness)
+	if err != nil || len(newsList) != 1 || newsList[0].Topic != TopicBusiness {
+		t.Errorf("topic: got %+v, %v", newsList, err)
+	}
+	if _, err := p.GetTopicNews(TopicSports); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("topic without feeds: got %v, want ErrNotSupported", err)
+	}
+	if _, err := NewFeedProvider([]string{"ftp://example.com/feed"}).GetTopNews(); !errors.Is(err, ErrInvalidFeedURL) {
+		t.Errorf("ftp feed: got %v, want ErrInvalidFeedURL", err)
+	}
+}
+
+func TestCombinedProvider(t *testing.T) {
+	server := newProviderTestServer(t)
+	baseURL, _ := url.Parse(server.URL)
+	failing, _ := url.Parse(server.URL + "/failing/")
+	p := NewCombinedProvider(
+		NewBingProvider(WithProviderBaseURL(baseURL)),
+		NewGDELTProvider(WithProviderBaseURL(baseURL)),
+	)
+
+	// the news of both providers with the same source link are merged
+	newsList, err := p.SearchNews("chips")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newsList) != 2 {
+		t.Errorf("search: got %d news, want 2", len(newsList))
+	}
+	// gdelt does not support the top news
+	if newsList, err := p.GetTopNews(); err != nil || len(newsList) != 1 {
+		t.Errorf("top: got %d news, %v", len(newsList), err)
+	}
+
+	p = NewCombinedProvider(NewBingProvider(WithProviderBaseURL(baseURL)), NewBingProvider(WithProviderBaseURL(failing)))
+	newsList, err = p.GetTopNews()
+	var providerErrs ProviderErrors
+	if !errors.As(err, &providerErrs) || len(providerErrs) != 1 || len(newsList) != 1 {
+		t.Errorf("one failing provider: got %d news, %v", len(newsList), err)
+	}
+}
+
+func TestProviderHeaders(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	edition, err := LookupEdition(LanguagePortugueseBrasil, LocationBrazil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers := NewHeaders(WithHeaderProfiles(ProfileFirefoxLinux))
+	p := NewGDELTProvider(WithProviderBaseURL(baseURL), WithProviderEdition(edition), WithProviderHeaders(headers))
+	p.SearchNews("chips")
+	if header.Get("User-Agent") != ProfileFirefoxLinux.UserAgent {
+		t.Errorf("got User-Agent %q, want %q", header.Get("User-Agent"), ProfileFirefoxLinux.UserAgent)
+	}
+	// the language tag of the edition, not its content language "pt-419"
+	if language := header.Get("Accept-Language"); !strings.HasPrefix(language, "pt-BR,") {
+		t.Errorf("got Accept-Language %q", language)
+	}
+
+	if NewGDELTProvider().headers == NewGDELTProvider().headers {
+		t.Error("two providers share their headers")
+	}
+}
@@ -0,0 +1,122 @@
+package newsapi
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Zhima-Mochi/newsApi-go/newsapi/newsapitest"
+)
+
+// newTestProxy returns an http proxy forwarding the requests to the transport,
+// the https requests are tunneled to a tls server forwarding them too
+func newTestProxy(transport http.RoundTripper, requests *int32) (proxy *httptest.Server, tlsServer *httptest.Server) {
+	forward := func(w http.ResponseWriter, r *http.Request, scheme string) {
+		req := r.Clone(r.Context())
+		req.RequestURI = ""
+		req.URL.Scheme = scheme
+		req.URL.Host = r.Host
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		for key, values := range resp.Header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}
+	tlsServer = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forward(w, r, "https")
+	}))
+	proxy = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if r.Method != http.MethodConnect {
+			forward(w, r, "http")
+			return
+		}
+		target, err := net.Dial("tcp", tlsServer.Listener.Addr().String())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			target.Close()
+			return
+		}
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		go func() {
+			io.Copy(target, conn)
+			target.Close()
+		}()
+		io.Copy(conn, target)
+		conn.Close()
+	}))
+	return proxy, tlsServer
+}
+
+func TestProxyPoolFetchSources(t *testing.T) {
+	server := newsapitest.NewServer()
+	defer server.Close()
+	articles := testArticles(server)
+	server.SetTopNews(newsapitest.Fixture{Articles: articles})
+	server.SetPage("/articles/chips", newsapitest.Fixture{Body: `<html><head><meta property="og:site_name" content="Reuters"></head></html>`})
+
+	var requests int32
+	proxy, tlsServer := newTestProxy(server.Transport(), &requests)
+	defer tlsServer.Close()
+	defer proxy.Close()
+	pool, err := NewProxyPool([]string{proxy.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the tls config is set after the pool, it still applies to the pool
+	api := NewNewsApi(WithBaseURL(server.BaseURL()), WithProxyPool(pool), WithTLSConfig(&tls.Config{InsecureSkipVerify: true}))
+	newsList, err := api.GetTopNews()
+	if err != nil {
+		t.Fatal(err)
+	}
+	feedRequests := atomic.LoadInt32(&requests)
+	if feedRequests == 0 {
+		t.Fatal("the feed request did not go through the pool")
+	}
+	api.FetchSourceLinks(newsList)
+	api.FetchSourceContents(newsList[:1])
+	if newsList[0].SourceSiteName != "Reuters" {
+		t.Errorf("got source contents %+v", newsList[0])
+	}
+	if atomic.LoadInt32(&requests) == feedRequests {
+		t.Error("the source requests did not go through the pool")
+	}
+}
+
+func TestProxyPoolTransportOptions(t *testing.T) {
+	pool, err := NewProxyPool([]string{"http://proxy1.example.com:8080", "http://proxy2.example.com:8080"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsConfig := &tls.Config{ServerName: "example.com"}
+	otherProxy, _ := url.Parse("http://other.example.com:8080")
+	api := NewNewsApi(WithProxyPool(pool), WithTLSConfig(tlsConfig), WithProxy(otherProxy))
+	if api.client.Transport != pool {
+		t.Fatalf("got transport %T", api.client.Transport)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://news.google.com/rss", nil)
+	for i, proxy := range pool.proxies {
+		transport := pool.transport(proxy)
+		if transport.TLSClientConfig == nil || transport.TLSClientConfig.ServerName != tlsConfig.ServerName {
+			t.Errorf("proxy %d: got tls config %v", i, transport.TLSClientConfig)
+		}
+		if u, _ := transport.Proxy(req); u == nil || u.String() != proxy.url.String() {
+			t.Errorf("proxy %d: got proxy %v, want %v", i, u, proxy.url)
+		}
+	}
+}
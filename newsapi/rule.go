@@ -0,0 +1,384 @@
+package newsapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Field is a text field of News checked by a condition
+type Field int
+
+const (
+	// FieldAny checks the title, description and source content
+	FieldAny Field = iota
+	FieldTitle
+	FieldDescription
+	// FieldContent checks the source content, which is only set once the news is enriched by FetchSourceContents
+	FieldContent
+)
+
+func (f Field) String() string {
+	switch f {
+	case FieldTitle:
+		return "title"
+	case FieldDescription:
+		return "description"
+	case FieldContent:
+		return "content"
+	default:
+		return "any"
+	}
+}
+
+type fieldText struct {
+	field Field
+	text  string
+}
+
+// texts returns the texts of the field of the news
+func (f Field) texts(news *News) []fieldText {
+	switch f {
+	case FieldTitle:
+		return []fieldText{{FieldTitle, news.Title}}
+	case FieldDescription:
+		return []fieldText{{FieldDescription, news.Description}}
+	case FieldContent:
+		return []fieldText{{FieldContent, news.SourceContent}}
+	default:
+		return []fieldText{
+			{FieldTitle, news.Title},
+			{FieldDescription, news.Description},
+			{FieldContent, news.SourceContent},
+		}
+	}
+}
+
+// Evidence is what made a condition match
+type Evidence struct {
+	// Condition describes the condition, e.g. `keyword "tsmc"`
+	Condition string
+	// Field is the field where the match was found, empty for conditions not on text
+	Field string
+	// Match is the matched text
+	Match string
+}
+
+// Condition is a boolean condition over a news
+type Condition interface {
+	Match(news *News) (bool, []Evidence)
+}
+
+type keywordCondition struct {
+	field    Field
+	keywords []string
+}
+
+// Keyword matches news containing any of the keywords in the field, ignoring case
+func Keyword(field Field, keywords ...string) Condition {
+	return &keywordCondition{field: field, keywords: keywords}
+}
+
+// indexFold returns the byte bounds of the first match of keyword in text ignoring case, or -1, -1.
+// The runes are compared one by one, since case folding may change their byte length, e.g. the Kelvin sign.
+func indexFold(text, keyword string) (int, int) {
+	for start := range text {
+		end := start
+		matched := true
+		for _, k := range keyword {
+			if end >= len(text) {
+				matched = false
+				break
+			}
+			r, size := utf8.DecodeRuneInString(text[end:])
+			if r != k && !strings.EqualFold(string(r), string(k)) {
+				matched = false
+				break
+			}
+			end += size
+		}
+		if matched {
+			return start, end
+		}
+	}
+	return -1, -1
+}
+
+func (c *keywordCondition) Match(news *News) (bool, []Evidence) {
+	var evidence []Evidence
+	for _, ft := range c.field.texts(news) {
+		for _, keyword := range c.keywords {
+			if keyword == "" {
+				continue
+			}
+			start, end := indexFold(ft.text, keyword)
+			if start < 0 {
+				continue
+			}
+			evidence = append(evidence, Evidence{
+				Condition: fmt.Sprintf("keyword %q", keyword),
+				Field:     ft.field.String(),
+				Match:     ft.text[start:end],
+			})
+		}
+	}
+	return len(evidence) > 0, evidence
+}
+
+type regexCondition struct {
+	field Field
+	re    *regexp.Regexp
+}
+
+// Regex matches news whose field matches the regular expression
+func Regex(field Field, pattern string) (Condition, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling regex: %w", err)
+	}
+	return &regexCondition{field: field, re: re}, nil
+}
+
+// MustRegex is like Regex but panics if the pattern cannot be compiled
+func MustRegex(field Field, pattern string) Condition {
+	c, err := Regex(field, pattern)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func (c *regexCondition) Match(news *News) (bool, []Evidence) {
+	var evidence []Evidence
+	for _, ft := range c.field.texts(news) {
+		if match := c.re.FindString(ft.text); match != "" {
+			evidence = append(evidence, Evidence{
+				Condition: fmt.Sprintf("regex %q", c.re.String()),
+				Field:     ft.field.String(),
+				Match:     match,
+			})
+		}
+	}
+	return len(evidence) > 0, evidence
+}
+
+type publisherCondition struct {
+	publishers []string
+}
+
+// Publisher matches news of any of the publishers, ignoring case, see News.Publisher
+func Publisher(publishers ...string) Condition {
+	return &publisherCondition{publishers: publishers}
+}
+
+func (c *publisherCondition) Match(news *News) (bool, []Evidence) {
+	publisher := news.Publisher()
+	for _, p := range c.publishers {
+		if strings.EqualFold(p, publisher) {
+			return true, []Evidence{{Condition: fmt.Sprintf("publisher %q", p), Match: publisher}}
+		}
+	}
+	return false, nil
+}
+
+type topicCondition struct {
+	topics []string
+}
+
+// Topic matches news of any of the topics, from a topic query or the categories of the feed
+func Topic(topics ...string) Condition {
+	return &topicCondition{topics: topics}
+}
+
+func (c *topicCondition) Match(news *News) (bool, []Evidence) {
+	for _, topic := range c.topics {
+		if strings.EqualFold(topic, news.Topic) {
+			return true, []Evidence{{Condition: fmt.Sprintf("topic %q", topic), Match: news.Topic}}
+		}
+		for _, category := range news.Categories {
+			if strings.EqualFold(topic, category) {
+				return true, []Evidence{{Condition: fmt.Sprintf("topic %q", topic), Match: category}}
+			}
+		}
+	}
+	return false, nil
+}
+
+type andCondition []Condition
+
+// And matches news matching every condition
+func And(conditions ...Condition) Condition {
+	return andCondition(conditions)
+}
+
+func (c andCondition) Match(news *News) (bool, []Evidence) {
+	var evidence []Evidence
+	for _, condition := range c {
+		ok, e := condition.Match(news)
+		if !ok {
+			return false, nil
+		}
+		evidence = append(evidence, e...)
+	}
+	return len(c) > 0, evidence
+}
+
+type orCondition []Condition
+
+// Or matches news matching any condition
+func Or(conditions ...Condition) Condition {
+	return orCondition(conditions)
+}
+
+func (c orCondition) Match(news *News) (bool, []Evidence) {
+	var evidence []Evidence
+	matched := false
+	for _, condition := range c {
+		if ok, e := condition.Match(news); ok {
+			matched = true
+			evidence = append(evidence, e...)
+		}
+	}
+	return matched, evidence
+}
+
+type notCondition struct {
+	condition Condition
+}
+
+// Not matches news not matching the condition
+func Not(condition Condition) Condition {
+	return &notCondition{condition: condition}
+}
+
+func (c *notCondition) Match(news *News) (bool, []Evidence) {
+	ok, _ := c.condition.Match(news)
+	return !ok, nil
+}
+
+// Rule fires an alert for news matching its condition
+type Rule struct {
+	Name      string
+	Condition Condition
+	// Threshold makes the rule fire only when more than Threshold news match within Window, 0 fires on every match
+	Threshold int
+	Window    time.Duration
+}
+
+// Alert is fired by a rule
+type Alert struct {
+	Rule string
+	// News is the news which fired the alert
+	News *News
+	// Evidence is what matched in News
+	Evidence []Evidence
+	// Matches are the news matched within the window of a rate rule, including News
+	Matches []*News
+	Time    time.Time
+}
+
+// RuleEngine evaluates rules against incoming news
+type RuleEngine struct {
+	mu      sync.Mutex
+	rules   []Rule
+	history map[string][]ruleMatch
+}
+
+type ruleMatch struct {
+	news *News
+	at   time.Time
+}
+
+// NewRuleEngine returns a rule engine for the rules, names must be unique
+func NewRuleEngine(rules ...Rule) (*RuleEngine, error) {
+	e := &RuleEngine{
+		history: map[string][]ruleMatch{},
+	}
+	names := map[string]bool{}
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return nil, ErrEmptyRuleName
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateRuleName, rule.Name)
+		}
+		if rule.Condition == nil {
+			return nil, fmt.Errorf("%w: %s", ErrEmptyCondition, rule.Name)
+		}
+		if rule.Threshold > 0 && rule.Window <= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidWindow, rule.Name)
+		}
+		names[rule.Name] = true
+		e.rules = append(e.rules, rule)
+	}
+	return e, nil
+}
+
+// Evaluate evaluates every rule against the news and returns the fired alerts.
+// Rate rules count a news at its published date, or now if unknown, and start over after firing.
+func (e *RuleEngine) Evaluate(news *News) []*Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	var alerts []*Alert
+	for _, rule := range e.rules {
+		ok, evidence := rule.Condition.Match(news)
+		if !ok {
+			continue
+		}
+		alert := &Alert{
+			Rule:     rule.Name,
+			News:     news,
+			Evidence: evidence,
+			Matches:  []*News{news},
+			Time:     now,
+		}
+		if rule.Threshold <= 0 {
+			alerts = append(alerts, alert)
+			continue
+		}
+
+		at := now
+		if news.PublishedParsed != nil {
+			at = *news.PublishedParsed
+		}
+		// keep the matches within the window before the latest match
+		matches := append(e.history[rule.Name], ruleMatch{news: news, at: at})
+		latest := at
+		for _, m := range matches {
+			if m.at.After(latest) {
+				latest = m.at
+			}
+		}
+		kept := matches[:0]
+		for _, m := range matches {
+			if latest.Sub(m.at) < rule.Window {
+				kept = append(kept, m)
+			}
+		}
+		if len(kept) <= rule.Threshold {
+			e.history[rule.Name] = kept
+			continue
+		}
+		alert.Matches = make([]*News, 0, len(kept))
+		for _, m := range kept {
+			alert.Matches = append(alert.Matches, m.news)
+		}
+		alerts = append(alerts, alert)
+		delete(e.history, rule.Name)
+	}
+	return alerts
+}
+
+// EvaluateAll evaluates every news in order and returns all fired alerts
+func (e *RuleEngine) EvaluateAll(newsList []*News) []*Alert {
+	var alerts []*Alert
+	for _, news := range newsList {
+		alerts = append(alerts, e.Evaluate(news)...)
+	}
+	return alerts
+}
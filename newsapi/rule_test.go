@@ -0,0 +1,139 @@
+package newsapi
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIndexFold(t *testing.T) {
+	tests := []struct {
+		text, keyword string
+		want          string
+	}{
+		{"TSMC raises guidance", "tsmc", "TSMC"},
+		{"Shares of tsmc rose", "TSMC", "tsmc"},
+		{"ΣΟΦΊΑ Κεφαλαίου", "σοφία", "ΣΟΦΊΑ"},
+		{"Zürich ÜBERNIMMT", "übernimmt", "ÜBERNIMMT"},
+		// the kelvin sign folds to k but is 3 bytes long
+		{"500 \u212Aelvin", "kelvin", "\u212Aelvin"},
+		{"台積電 TSMC 營收", "tsmc 營收", "TSMC 營收"},
+		{"no match here", "tsmc", ""},
+		{"ts", "tsmc", ""},
+	}
+	for _, tt := range tests {
+		start, end := indexFold(tt.text, tt.keyword)
+		got := ""
+		if start >= 0 {
+			got = tt.text[start:end]
+		}
+		if got != tt.want {
+			t.Errorf("indexFold(%q, %q): got %q, want %q", tt.text, tt.keyword, got, tt.want)
+		}
+	}
+}
+
+func TestConditions(t *testing.T) {
+	news := &News{
+		Title:       "TSMC raises guidance - Reuters",
+		Description: "Chip maker sees strong AI demand",
+		Provider:    ProviderGoogle,
+		Categories:  []string{"Technology"},
+	}
+	tests := []struct {
+		name      string
+		condition Condition
+		want      bool
+		evidence  []Evidence
+	}{
+		{"keyword", Keyword(FieldAny, "tsmc", "ai"), true, []Evidence{
+			{Condition: `keyword "tsmc"`, Field: "title", Match: "TSMC"},
+			{Condition: `keyword "ai"`, Field: "title", Match: "ai"},
+			{Condition: `keyword "ai"`, Field: "description", Match: "AI"},
+		}},
+		{"keyword in another field", Keyword(FieldContent, "tsmc"), false, nil},
+		{"empty keyword", Keyword(FieldAny, ""), false, nil},
+		{"regex", MustRegex(FieldDescription, `(?i)\bai\b`), true, []Evidence{{Condition: `regex "(?i)\\bai\\b"`, Field: "description", Match: "AI"}}},
+		{"publisher", Publisher("reuters"), true, []Evidence{{Condition: `publisher "reuters"`, Match: "Reuters"}}},
+		{"other publisher", Publisher("AP News"), false, nil},
+		{"category", Topic(TopicTechnology), true, []Evidence{{Condition: `topic "TECHNOLOGY"`, Match: "Technology"}}},
+		{"and", And(Publisher("Reuters"), Topic(TopicBusiness)), false, nil},
+		{"empty and", And(), false, nil},
+		{"or", Or(Topic(TopicBusiness), Publisher("Reuters")), true, []Evidence{{Condition: `publisher "Reuters"`, Match: "Reuters"}}},
+		{"not", Not(Topic(TopicBusiness)), true, nil},
+	}
+	for _, tt := range tests {
+		ok, evidence := tt.condition.Match(news)
+		if ok != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, ok, tt.want)
+		}
+		if len(evidence) != len(tt.evidence) {
+			t.Errorf("%s: got evidence %+v, want %+v", tt.name, evidence, tt.evidence)
+			continue
+		}
+		for i := range evidence {
+			if evidence[i] != tt.evidence[i] {
+				t.Errorf("%s: got evidence %+v, want %+v", tt.name, evidence[i], tt.evidence[i])
+			}
+		}
+	}
+	if _, err := Regex(FieldAny, "("); err == nil {
+		t.Error("invalid regex: got no error")
+	}
+}
+
+func TestNewRuleEngineErrors(t *testing.T) {
+	condition := Keyword(FieldAny, "tsmc")
+	tests := []struct {
+		rules []Rule
+		want  error
+	}{
+		{[]Rule{{Condition: condition}}, ErrEmptyRuleName},
+		{[]Rule{{Name: "a", Condition: condition}, {Name: "a", Condition: condition}}, ErrDuplicateRuleName},
+		{[]Rule{{Name: "a"}}, ErrEmptyCondition},
+		{[]Rule{{Name: "a", Condition: condition, Threshold: 2}}, ErrInvalidWindow},
+	}
+	for _, tt := range tests {
+		if _, err := NewRuleEngine(tt.rules...); !errors.Is(err, tt.want) {
+			t.Errorf("got %v, want %v", err, tt.want)
+		}
+	}
+}
+
+func TestRuleEngineEvaluate(t *testing.T) {
+	engine, err := NewRuleEngine(
+		Rule{Name: "tsmc", Condition: Keyword(FieldTitle, "tsmc")},
+		Rule{Name: "surge", Condition: Keyword(FieldTitle, "chip"), Threshold: 2, Window: time.Hour},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	chip := func(minutes int, title string) *News {
+		at := base.Add(time.Duration(minutes) * time.Minute)
+		return &News{Title: title, PublishedParsed: &at}
+	}
+
+	alerts := engine.Evaluate(chip(0, "TSMC chip output"))
+	if len(alerts) != 1 || alerts[0].Rule != "tsmc" || alerts[0].Evidence[0].Match != "TSMC" {
+		t.Fatalf("got alerts %+v, want the tsmc alert only", alerts)
+	}
+	// the first match left the window when the third one arrives
+	if alerts := engine.EvaluateAll([]*News{chip(50, "Chip stocks rise"), chip(70, "Chip shortage eases")}); len(alerts) != 0 {
+		t.Fatalf("got alerts %+v before the threshold", alerts)
+	}
+	alerts = engine.Evaluate(chip(80, "Chip exports rise"))
+	if len(alerts) != 1 || alerts[0].Rule != "surge" || len(alerts[0].Matches) != 3 {
+		t.Fatalf("got alerts %+v, want the surge alert with 3 matches", alerts)
+	}
+	if alerts[0].Matches[0].Title != "Chip stocks rise" || alerts[0].News.Title != "Chip exports rise" {
+		t.Errorf("got matches %v", alerts[0].Matches)
+	}
+	// the rate rule starts over after firing
+	if alerts := engine.Evaluate(chip(90, "Chip prices fall")); len(alerts) != 0 {
+		t.Errorf("got alerts %+v right after firing", alerts)
+	}
+	if alerts := engine.Evaluate(&News{Title: "Rain expected"}); len(alerts) != 0 {
+		t.Errorf("got alerts %+v for an unmatched news", alerts)
+	}
+}
@@ -0,0 +1,357 @@
+package newsapi
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultBM25K1 = 1.2
+	defaultBM25B  = 0.75
+)
+
+// SearchResult is a news found by a SearchIndex
+type SearchResult struct {
+	News  *News
+	Score float64
+}
+
+// SearchIndex is an in-memory inverted index over the title, description and source content of news, ranking them with bm25
+type SearchIndex struct {
+	mu       sync.RWMutex
+	language string
+	k1       float64
+	b        float64
+
+	// docs are the indexed news by id, removed news are nil
+	docs     []*indexDoc
+	byGUID   map[string]int
+	byLink   map[string]int
+	postings map[string]map[int][]int
+	count    int
+	length   int
+}
+
+type indexDoc struct {
+	news   *News
+	length int
+	terms  []string
+}
+
+type indexTerm struct {
+	term     string
+	position int
+}
+
+type SearchIndexOption func(*SearchIndex)
+
+// WithSearchLanguage sets the language of the tokenization, english by default.
+// English words are stemmed and english stop words are ignored, CJK text is split into bigrams in every language.
+func WithSearchLanguage(language string) SearchIndexOption {
+	return func(x *SearchIndex) {
+		x.language = language
+	}
+}
+
+// WithBM25 sets the k1 and b parameters of the bm25 ranking, 1.2 and 0.75 by default
+func WithBM25(k1, b float64) SearchIndexOption {
+	return func(x *SearchIndex) {
+		if k1 >= 0 && b >= 0 && b <= 1 {
+			x.k1, x.b = k1, b
+		}
+	}
+}
+
+// NewSearchIndex returns an empty search index
+func NewSearchIndex(options ...SearchIndexOption) *SearchIndex {
+	x := &SearchIndex{
+		language: LanguageEnglish,
+		k1:       defaultBM25K1,
+		b:        defaultBM25B,
+		byGUID:   map[string]int{},
+		byLink:   map[string]int{},
+		postings: map[string]map[int][]int{},
+	}
+	for _, option := range options {
+		option(x)
+	}
+	return x
+}
+
+// analyze returns the terms of the text with their positions.
+// Ignored stop words still take a position, so that phrases keep their gaps.
+func (x *SearchIndex) analyze(text string, position int) []indexTerm {
+	english := x.language == LanguageEnglish
+	var terms []indexTerm
+	for _, token := range tokenize(text) {
+		if english {
+			if _, ok := stopWords[token]; ok {
+				position++
+				continue
+			}
+			token = stem(token)
+		}
+		terms = append(terms, indexTerm{term: token, position: position})
+		position++
+	}
+	return terms
+}
+
+// Len returns the number of indexed news
+func (x *SearchIndex) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.count
+}
+
+// Add indexes the news, replacing the indexed news with the same guid or link
+func (x *SearchIndex) Add(newsList ...*News) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, news := range newsList {
+		guid, linkKey, err := storeKeys(news)
+		if err != nil {
+			return err
+		}
+		if id, ok := x.byGUID[guid]; ok && guid != "" {
+			x.remove(id)
+		}
+		if id, ok := x.byLink[linkKey]; ok && linkKey != "" {
+			x.remove(id)
+		}
+
+		id := len(x.docs)
+		doc := &indexDoc{news: news}
+		positions := map[string][]int{}
+		position := 0
+		for _, text := range []string{news.Title, news.Description, news.SourceContent} {
+			for _, t := range x.analyze(text, position) {
+				positions[t.term] = append(positions[t.term], t.position)
+				doc.length++
+				position = t.position + 1
+			}
+			// a gap so that phrases do not span fields
+			position++
+		}
+		for term, p := range positions {
+			if x.postings[term] == nil {
+				x.postings[term] = map[int][]int{}
+			}
+			x.postings[term][id] = p
+			doc.terms = append(doc.terms, term)
+		}
+		x.docs = append(x.docs, doc)
+		x.count++
+		x.length += doc.length
+		if guid != "" {
+			x.byGUID[guid] = id
+		}
+		if linkKey != "" {
+			x.byLink[linkKey] = id
+		}
+	}
+	return nil
+}
+
+// Remove removes the news with the guid or link from the index
+func (x *SearchIndex) Remove(key string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if id, ok := x.byGUID[key]; ok {
+		x.remove(id)
+	}
+	if id, ok := x.byLink[CanonicalURL(key)]; ok {
+		x.remove(id)
+	}
+}
+
+func (x *SearchIndex) remove(id int) {
+	doc := x.docs[id]
+	if doc == nil {
+		return
+	}
+	for _, term := range doc.terms {
+		delete(x.postings[term], id)
+		if len(x.postings[term]) == 0 {
+			delete(x.postings, term)
+		}
+	}
+	guid, linkKey, _ := storeKeys(doc.news)
+	if x.byGUID[guid] == id {
+		delete(x.byGUID, guid)
+	}
+	if x.byLink[linkKey] == id {
+		delete(x.byLink, linkKey)
+	}
+	x.docs[id] = nil
+	x.count--
+	x.length -= doc.length
+}
+
+// searchClause is a term or a phrase of a search query
+type searchClause []indexTerm
+
+// parseSearchQuery splits the query into clauses, quoted text is a phrase and every other word a term
+func (x *SearchIndex) parseSearchQuery(query string) []searchClause {
+	var clauses []searchClause
+	for i, part := range strings.Split(query, `"`) {
+		terms := x.analyze(part, 0)
+		if i%2 == 1 {
+			if len(terms) > 0 {
+				clauses = append(clauses, terms)
+			}
+			continue
+		}
+		for _, t := range terms {
+			clauses = append(clauses, searchClause{{term: t.term}})
+		}
+	}
+	return clauses
+}
+
+// Search returns the news containing every term and phrase of query.Text, the best ranked first.
+// Phrases are quoted, e.g. `"interest rates" fed`, and the other fields of the query filter the news.
+func (x *SearchIndex) Search(query StoreQuery) ([]*SearchResult, error) {
+	clauses := x.parseSearchQuery(query.Text)
+	if len(clauses) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	// the matches of every clause, by document, sorted by selectivity
+	matches := make([]map[int]int, len(clauses))
+	for i, clause := range clauses {
+		matches[i] = x.match(clause)
+		if len(matches[i]) == 0 {
+			return nil, nil
+		}
+	}
+	order := make([]int, len(clauses))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return len(matches[order[i]]) < len(matches[order[j]]) })
+
+	avgLength := float64(x.length) / float64(x.count)
+	candidates := make([]int, 0, len(matches[order[0]]))
+	for id := range matches[order[0]] {
+		candidates = append(candidates, id)
+	}
+	sort.Ints(candidates)
+	var results []*SearchResult
+	for _, id := range candidates {
+		doc := x.docs[id]
+		score := 0.0
+		for _, i := range order {
+			frequency, ok := matches[i][id]
+			if !ok {
+				score = -1
+				break
+			}
+			idf := math.Log(1 + (float64(x.count)-float64(len(matches[i]))+0.5)/(float64(len(matches[i]))+0.5))
+			tf := float64(frequency)
+			score += idf * tf * (x.k1 + 1) / (tf + x.k1*(1-x.b+x.b*float64(doc.length)/avgLength))
+		}
+		if score < 0 || !query.match(doc.news, nil) {
+			continue
+		}
+		results = append(results, &SearchResult{News: doc.news, Score: score})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return dateAfter(results[i].News.PublishedParsed, results[j].News.PublishedParsed)
+	})
+	if query.Offset > 0 {
+		if query.Offset >= len(results) {
+			return nil, nil
+		}
+		results = results[query.Offset:]
+	}
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+	return results, nil
+}
+
+// match returns the frequency of the clause in every document containing it
+func (x *SearchIndex) match(clause searchClause) map[int]int {
+	if len(clause) == 1 {
+		if runes := []rune(clause[0].term); len(runes) == 1 && isCJK(runes[0]) {
+			return x.matchCJK(runes[0])
+		}
+	}
+	first := x.postings[clause[0].term]
+	frequencies := make(map[int]int, len(first))
+	if len(clause) == 1 {
+		for id, positions := range first {
+			frequencies[id] = len(positions)
+		}
+		return frequencies
+	}
+	for id, positions := range first {
+		count := 0
+		for _, start := range positions {
+			found := true
+			for _, t := range clause[1:] {
+				if !containsPosition(x.postings[t.term][id], start+t.position-clause[0].position) {
+					found = false
+					break
+				}
+			}
+			if found {
+				count++
+			}
+		}
+		if count > 0 {
+			frequencies[id] = count
+		}
+	}
+	return frequencies
+}
+
+// matchCJK returns the frequency of a single CJK character in every document containing it.
+// CJK text is indexed as bigrams, so the character is found in the bigrams it starts or ends, at their positions.
+func (x *SearchIndex) matchCJK(r rune) map[int]int {
+	positions := map[int]map[int]bool{}
+	add := func(postings map[int][]int, offset int) {
+		for id, p := range postings {
+			if positions[id] == nil {
+				positions[id] = map[int]bool{}
+			}
+			for _, position := range p {
+				positions[id][position+offset] = true
+			}
+		}
+	}
+	for term, postings := range x.postings {
+		runes := []rune(term)
+		if len(runes) > 2 || !isCJK(runes[0]) {
+			continue
+		}
+		if runes[0] == r {
+			add(postings, 0)
+		}
+		// the second character of a bigram is at the position of the next bigram
+		if len(runes) == 2 && runes[1] == r {
+			add(postings, 1)
+		}
+	}
+	frequencies := make(map[int]int, len(positions))
+	for id, p := range positions {
+		frequencies[id] = len(p)
+	}
+	return frequencies
+}
+
+// containsPosition checks whether the sorted positions contain position
+func containsPosition(positions []int, position int) bool {
+	i := sort.SearchInts(positions, position)
+	return i < len(positions) && positions[i] == position
+}
@@ -0,0 +1,92 @@
+package newsapi
+
+import (
+	"errors"
+	"testing"
+)
+
+func searchTitles(t *testing.T, x *SearchIndex, text string) []string {
+	t.Helper()
+	results, err := x.Search(StoreQuery{Text: text})
+	if err != nil {
+		t.Fatalf("Search %q: %v", text, err)
+	}
+	var titles []string
+	for _, result := range results {
+		titles = append(titles, result.News.Title)
+	}
+	return titles
+}
+
+func TestSearchIndex(t *testing.T) {
+	x := NewSearchIndex()
+	err := x.Add(
+		&News{GUID: "1", Title: "Central bank raises interest rates", Description: "The rates were raised again"},
+		&News{GUID: "2", Title: "Interest in rates of housing", Description: "Buyers are worried"},
+		&News{GUID: "3", Title: "Running shoes review"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"rates", []string{"Central bank raises interest rates", "Interest in rates of housing"}},
+		{`"interest rates"`, []string{"Central bank raises interest rates"}},
+		{"reviews", []string{"Running shoes review"}},
+		{"bank housing", nil},
+	}
+	for _, tt := range tests {
+		got := searchTitles(t, x, tt.text)
+		if len(got) != len(tt.want) {
+			t.Errorf("Search %q: got %q, want %q", tt.text, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Search %q: got %q, want %q", tt.text, got, tt.want)
+				break
+			}
+		}
+	}
+	if _, err := x.Search(StoreQuery{Text: "the"}); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("Search of a stop word: got %v, want ErrEmptyQuery", err)
+	}
+
+	x.Remove("1")
+	if got := searchTitles(t, x, "bank"); len(got) != 0 {
+		t.Errorf("Search after Remove: got %q", got)
+	}
+}
+
+func TestSearchIndexCJK(t *testing.T) {
+	x := NewSearchIndex()
+	err := x.Add(
+		&News{GUID: "1", Title: "台積電宣布擴大投資"},
+		&News{GUID: "2", Title: "日本央行維持利率"},
+		&News{GUID: "3", Title: "電"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"台積電", 1},
+		{"投資", 1},
+		// single characters are found within the bigrams, at their start, middle or end
+		{"台", 1},
+		{"積", 1},
+		{"資", 1},
+		{"電", 2},
+		{"率", 1},
+		{"股", 0},
+	}
+	for _, tt := range tests {
+		if got := searchTitles(t, x, tt.text); len(got) != tt.want {
+			t.Errorf("Search %q: got %q, want %d news", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package newsapi
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// SeenStore records the keys of the news already seen by a Watcher
+type SeenStore interface {
+	Seen(key string) (bool, error)
+	MarkSeen(keys ...string) error
+}
+
+var (
+	_ SeenStore = (*memorySeenStore)(nil)
+	_ SeenStore = (*fileSeenStore)(nil)
+)
+
+// memorySeenStore keeps the seen keys in memory, evicting the oldest ones beyond its capacity
+type memorySeenStore struct {
+	mu       sync.Mutex
+	capacity int
+	keys     map[string]struct{}
+	order    []string
+}
+
+// NewMemorySeenStore returns a seen store in memory keeping at most capacity keys, 0 means no limit
+func NewMemorySeenStore(capacity int) *memorySeenStore {
+	return &memorySeenStore{
+		capacity: capacity,
+		keys:     map[string]struct{}{},
+	}
+}
+
+func (s *memorySeenStore) Seen(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.keys[key]
+	return ok, nil
+}
+
+func (s *memorySeenStore) MarkSeen(keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		if _, ok := s.keys[key]; ok {
+			continue
+		}
+		s.keys[key] = struct{}{}
+		s.order = append(s.order, key)
+	}
+	if s.capacity > 0 && len(s.order) > s.capacity {
+		evicted := len(s.order) - s.capacity
+		for _, key := range s.order[:evicted] {
+			delete(s.keys, key)
+		}
+		s.order = append([]string(nil), s.order[evicted:]...)
+	}
+	return nil
+}
+
+// fileSeenStore keeps the seen keys in memory and appends them to a file, one key per line,
+// so that keys with a line break are rejected
+type fileSeenStore struct {
+	mu   sync.Mutex
+	file *os.File
+	keys map[string]struct{}
+}
+
+// NewFileSeenStore returns a seen store backed by the file at path, loading the keys it already contains
+func NewFileSeenStore(path string) (*fileSeenStore, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening seen store: %w", err)
+	}
+	s := &fileSeenStore{
+		file: file,
+		keys: map[string]struct{}{},
+	}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if key := scanner.Text(); key != "" {
+			s.keys[key] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error reading seen store: %w", err)
+	}
+	return s, nil
+}
+
+func (s *fileSeenStore) Seen(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.keys[key]
+	return ok, nil
+}
+
+// MarkSeen writes the keys to the file before keeping them in memory,
+// so that on error the keys seen in memory are the ones in the file
+func (s *fileSeenStore) MarkSeen(keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fresh := make(map[string]struct{}, len(keys))
+	var lines strings.Builder
+	for _, key := range keys {
+		if strings.ContainsAny(key, "\r\n") {
+			return fmt.Errorf("%w: %q", ErrInvalidSeenKey, key)
+		}
+		if _, ok := s.keys[key]; ok {
+			continue
+		}
+		if _, ok := fresh[key]; ok {
+			continue
+		}
+		fresh[key] = struct{}{}
+		lines.WriteString(key + "\n")
+	}
+	if len(fresh) == 0 {
+		return nil
+	}
+	info, err := s.file.Stat()
+	if err != nil {
+		return fmt.Errorf("error writing seen store: %w", err)
+	}
+	if _, err := s.file.WriteString(lines.String()); err != nil {
+		// a partly written key would be read as another key
+		s.file.Truncate(info.Size())
+		return fmt.Errorf("error writing seen store: %w", err)
+	}
+	for key := range fresh {
+		s.keys[key] = struct{}{}
+	}
+	return nil
+}
+
+// Close closes the file of the store
+func (s *fileSeenStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
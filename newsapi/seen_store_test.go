@@ -0,0 +1,60 @@
+package newsapi
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemorySeenStore(t *testing.T) {
+	store := NewMemorySeenStore(2)
+	if err := store.MarkSeen("a", "b", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.MarkSeen("c"); err != nil {
+		t.Fatal(err)
+	}
+	// the oldest key is evicted beyond the capacity
+	for key, want := range map[string]bool{"a": false, "b": true, "c": true, "d": false} {
+		if seen, _ := store.Seen(key); seen != want {
+			t.Errorf("Seen(%q): got %v, want %v", key, seen, want)
+		}
+	}
+}
+
+func TestFileSeenStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seen.txt")
+	store, err := NewFileSeenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.MarkSeen("a", "b", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.MarkSeen("c", "bad\nkey"); !errors.Is(err, ErrInvalidSeenKey) {
+		t.Errorf("key with a line break: got %v, want ErrInvalidSeenKey", err)
+	}
+	if seen, _ := store.Seen("c"); seen {
+		t.Error("the keys of a rejected call were marked as seen")
+	}
+	store.Close()
+
+	store, err = NewFileSeenStore(path)
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	for key, want := range map[string]bool{"a": true, "b": true, "c": false, "bad": false, "key": false} {
+		if seen, _ := store.Seen(key); seen != want {
+			t.Errorf("Seen(%q) after reopening: got %v, want %v", key, seen, want)
+		}
+	}
+
+	// a key which cannot be written is not seen in memory either
+	store.file.Close()
+	if err := store.MarkSeen("d"); err == nil {
+		t.Fatal("MarkSeen to a closed file: got no error")
+	}
+	if seen, _ := store.Seen("d"); seen {
+		t.Error("an unwritten key was marked as seen")
+	}
+}
@@ -0,0 +1,69 @@
+package newsapi
+
+import (
+	"sort"
+	"time"
+)
+
+// SortOrder is the order of the news returned by a query
+type SortOrder int
+
+const (
+	// SortByPublishedDesc sorts the newest news first, this is the default order
+	SortByPublishedDesc SortOrder = iota
+	// SortByPublishedAsc sorts the oldest news first
+	SortByPublishedAsc
+	// SortByUpdatedDesc sorts the most recently updated news first, falling back to the published date
+	SortByUpdatedDesc
+	// SortByFeedOrder keeps the order of the feed, news from several feeds are kept feed by feed
+	SortByFeedOrder
+	// SortByRelevance keeps the ranking returned by google news, news from several feeds are interleaved by rank
+	SortByRelevance
+)
+
+// SortNews sorts the news in place.
+// News without a date are always sorted last, keeping their relative order.
+func SortNews(newsList []*News, order SortOrder) {
+	switch order {
+	case SortByFeedOrder:
+		return
+	case SortByRelevance:
+		sort.SliceStable(newsList, func(i, j int) bool {
+			return newsList[i].feedRank < newsList[j].feedRank
+		})
+	case SortByPublishedAsc:
+		sort.SliceStable(newsList, func(i, j int) bool {
+			return dateBefore(newsList[i].PublishedParsed, newsList[j].PublishedParsed)
+		})
+	case SortByUpdatedDesc:
+		sort.SliceStable(newsList, func(i, j int) bool {
+			return dateAfter(newsList[i].updatedOrPublished(), newsList[j].updatedOrPublished())
+		})
+	default:
+		sort.SliceStable(newsList, func(i, j int) bool {
+			return dateAfter(newsList[i].PublishedParsed, newsList[j].PublishedParsed)
+		})
+	}
+}
+
+// dateAfter reports whether a is after b, nil dates are never after a known date
+func dateAfter(a, b *time.Time) bool {
+	if a == nil {
+		return false
+	}
+	if b == nil {
+		return true
+	}
+	return a.After(*b)
+}
+
+// dateBefore reports whether a is before b, nil dates are never before a known date
+func dateBefore(a, b *time.Time) bool {
+	if a == nil {
+		return false
+	}
+	if b == nil {
+		return true
+	}
+	return a.Before(*b)
+}
@@ -0,0 +1,43 @@
+package newsapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSortNews(t *testing.T) {
+	at := func(hour int) *time.Time {
+		t := time.Date(2024, 5, 1, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+	newsList := func() []*News {
+		return []*News{
+			{GUID: "undated1", feedRank: 0},
+			{GUID: "9h", PublishedParsed: at(9), feedRank: 2},
+			{GUID: "8h", PublishedParsed: at(8), UpdatedParsed: at(12), feedRank: 1},
+			{GUID: "undated2", feedRank: 1},
+			{GUID: "10h", PublishedParsed: at(10), feedRank: 0},
+		}
+	}
+	tests := []struct {
+		order SortOrder
+		want  []string
+	}{
+		// news without a date are last, in their order
+		{SortByPublishedDesc, []string{"10h", "9h", "8h", "undated1", "undated2"}},
+		{SortByPublishedAsc, []string{"8h", "9h", "10h", "undated1", "undated2"}},
+		{SortByUpdatedDesc, []string{"8h", "10h", "9h", "undated1", "undated2"}},
+		{SortByFeedOrder, []string{"undated1", "9h", "8h", "undated2", "10h"}},
+		{SortByRelevance, []string{"undated1", "10h", "8h", "undated2", "9h"}},
+	}
+	for _, tt := range tests {
+		list := newsList()
+		SortNews(list, tt.order)
+		for i, news := range list {
+			if news.GUID != tt.want[i] {
+				t.Errorf("order %d: got %s at %d, want %v", tt.order, news.GUID, i, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,395 @@
+package newsapi
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store persists news, identified by their guid or source link
+type Store interface {
+	// Upsert inserts the news, or merges them into the stored news with the same guid or source link.
+	// Enriched fields of the stored news are kept when the new news lacks them.
+	Upsert(newsList ...*News) error
+	// Get returns the stored news with the guid or link, or ErrNewsNotFound
+	Get(key string) (*News, error)
+	// Query returns the stored news matching the query, the latest published first
+	Query(query StoreQuery) ([]*News, error)
+	Close() error
+}
+
+// StoreQuery selects stored news, its zero fields match every news
+type StoreQuery struct {
+	// From and To select the news published in [From, To)
+	From time.Time
+	To   time.Time
+	// Publisher selects the news of a publisher ignoring case, see News.Publisher
+	Publisher string
+	// Topic selects the news of a topic or category ignoring case
+	Topic string
+	// Text selects the news containing every word of the text in their title, description or source texts
+	Text  string
+	Limit int
+	// Offset skips the first news
+	Offset int
+}
+
+var (
+	_ Store = (*embeddedStore)(nil)
+	_ Store = (*sqliteStore)(nil)
+)
+
+// storeKeys returns the keys identifying the news in a store, its guid and its canonical link
+func storeKeys(news *News) (guid, linkKey string, err error) {
+	guid = news.GUID
+	if news.SourceLink != "" || news.Link != "" {
+		linkKey = news.dedupKey()
+	}
+	if guid == "" && linkKey == "" {
+		return "", "", ErrEmptyNewsKey
+	}
+	return guid, linkKey, nil
+}
+
+// mergeStoredNews returns the news updated by a newer fetch of it
+func mergeStoredNews(stored, news *News) *News {
+	merged := cloneNews(news)
+	keep := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	keep(&merged.GUID, stored.GUID)
+	keep(&merged.Description, stored.Description)
+	keep(&merged.Content, stored.Content)
+	keep(&merged.ImageURL, stored.ImageURL)
+	keep(&merged.SourceLink, stored.SourceLink)
+	keep(&merged.SourceTitle, stored.SourceTitle)
+	keep(&merged.SourceImageURL, stored.SourceImageURL)
+	keep(&merged.SourceDescription, stored.SourceDescription)
+	keep(&merged.SourceSiteName, stored.SourceSiteName)
+	keep(&merged.SourceIconUrl, stored.SourceIconUrl)
+	keep(&merged.SourceContent, stored.SourceContent)
+	keep(&merged.Topic, stored.Topic)
+	keep(&merged.Provider, stored.Provider)
+	if merged.PublishedParsed == nil {
+		merged.Published, merged.PublishedParsed = stored.Published, stored.PublishedParsed
+	}
+	if merged.SourceImageWidth == 0 && merged.SourceImageHeight == 0 {
+		merged.SourceImageWidth, merged.SourceImageHeight = stored.SourceImageWidth, stored.SourceImageHeight
+	}
+	if len(merged.SourceKeywords) == 0 {
+		merged.SourceKeywords = append([]string(nil), stored.SourceKeywords...)
+	}
+	merged.Links = appendStrings(append([]string(nil), stored.Links...), merged.Links...)
+	merged.Categories = appendStrings(append([]string(nil), stored.Categories...), merged.Categories...)
+	merged.Editions = appendEditions(append([]Edition(nil), stored.Editions...), merged.Editions...)
+	merged.Queries = appendStrings(append([]string(nil), stored.Queries...), merged.Queries...)
+	return merged
+}
+
+// cloneNews returns a copy of the news not sharing its slices
+func cloneNews(news *News) *News {
+	clone := *news
+	clone.Links = append([]string(nil), news.Links...)
+	clone.Categories = append([]string(nil), news.Categories...)
+	clone.SourceKeywords = append([]string(nil), news.SourceKeywords...)
+	clone.Editions = append([]Edition(nil), news.Editions...)
+	clone.Queries = append([]string(nil), news.Queries...)
+	return &clone
+}
+
+// storeText returns the texts of the news searched by StoreQuery.Text, lower cased
+func storeText(news *News) string {
+	return strings.ToLower(strings.Join([]string{
+		news.Title,
+		news.Description,
+		news.SourceTitle,
+		news.SourceDescription,
+		news.SourceContent,
+	}, " "))
+}
+
+// containsWords checks whether the text contains every word
+func containsWords(text string, words []string) bool {
+	for _, word := range words {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// match checks whether the news matches the query
+func (q StoreQuery) match(news *News, words []string) bool {
+	if !news.publishedBetween(q.From, q.To) {
+		return false
+	}
+	if q.Publisher != "" && !strings.EqualFold(q.Publisher, news.Publisher()) {
+		return false
+	}
+	if q.Topic != "" {
+		if ok, _ := Topic(q.Topic).Match(news); !ok {
+			return false
+		}
+	}
+	return len(words) == 0 || containsWords(storeText(news), words)
+}
+
+// embeddedStore keeps the news in memory, and optionally appends every upserted news to a file as json lines
+type embeddedStore struct {
+	mu   sync.RWMutex
+	path string
+	file *os.File
+	// news are indexed by the key maps, removed news are nil
+	news   []*News
+	count  int
+	byGUID map[string]int
+	byLink map[string]int
+}
+
+// NewMemoryStore returns a store in memory
+func NewMemoryStore() *embeddedStore {
+	return &embeddedStore{
+		byGUID: map[string]int{},
+		byLink: map[string]int{},
+	}
+}
+
+// NewFileStore returns a store backed by the file at path, loading the news it already contains.
+// The file is a log of json lines, the last line of a news wins, see Compact.
+func NewFileStore(path string) (*embeddedStore, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening store: %w", err)
+	}
+	s := NewMemoryStore()
+	s.path = path
+	s.file = file
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		news := &News{}
+		if err := json.Unmarshal(scanner.Bytes(), news); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("error reading store line %d: %w", line, err)
+		}
+		if err := s.put(news); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("error reading store line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error reading store: %w", err)
+	}
+	return s, nil
+}
+
+// find returns the indexes of the stored news with the guid or the link key, the one with the guid first
+func (s *embeddedStore) find(guid, linkKey string) []int {
+	var found []int
+	if i, ok := s.byGUID[guid]; ok && guid != "" {
+		found = append(found, i)
+	}
+	if i, ok := s.byLink[linkKey]; ok && linkKey != "" && (len(found) == 0 || found[0] != i) {
+		found = append(found, i)
+	}
+	return found
+}
+
+// put stores the news as is, replacing the stored news with the same guid or link
+func (s *embeddedStore) put(news *News) error {
+	guid, linkKey, err := storeKeys(news)
+	if err != nil {
+		return err
+	}
+	found := s.find(guid, linkKey)
+	i := len(s.news)
+	if len(found) == 0 {
+		s.news = append(s.news, news)
+		s.count++
+	} else {
+		i = found[0]
+		s.news[i] = news
+		// the news joins two stored news, the other one was merged into it
+		for _, j := range found[1:] {
+			s.remove(j, i)
+		}
+	}
+	if guid != "" {
+		s.byGUID[guid] = i
+	}
+	if linkKey != "" {
+		s.byLink[linkKey] = i
+	}
+	return nil
+}
+
+// remove removes the stored news j, its keys now identify the stored news i
+func (s *embeddedStore) remove(j, i int) {
+	s.news[j] = nil
+	s.count--
+	for key, k := range s.byGUID {
+		if k == j {
+			s.byGUID[key] = i
+		}
+	}
+	for key, k := range s.byLink {
+		if k == j {
+			s.byLink[key] = i
+		}
+	}
+}
+
+// appendNews appends the news to the file as a json line, a partly written line is truncated on error
+func (s *embeddedStore) appendNews(news *News) error {
+	line, err := json.Marshal(news)
+	if err != nil {
+		return fmt.Errorf("error encoding news: %w", err)
+	}
+	info, err := s.file.Stat()
+	if err != nil {
+		return fmt.Errorf("error writing store: %w", err)
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		s.file.Truncate(info.Size())
+		return fmt.Errorf("error writing store: %w", err)
+	}
+	return nil
+}
+
+// Upsert writes every news to the file before storing it in memory,
+// so that on error the news stored in memory are the ones in the file
+func (s *embeddedStore) Upsert(newsList ...*News) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, news := range newsList {
+		guid, linkKey, err := storeKeys(news)
+		if err != nil {
+			return err
+		}
+		stored := cloneNews(news)
+		if found := s.find(guid, linkKey); len(found) > 0 {
+			previous := s.news[found[len(found)-1]]
+			for k := len(found) - 2; k >= 0; k-- {
+				previous = mergeStoredNews(previous, s.news[found[k]])
+			}
+			stored = mergeStoredNews(previous, news)
+		}
+		if s.file != nil {
+			if err := s.appendNews(stored); err != nil {
+				return err
+			}
+		}
+		if err := s.put(stored); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *embeddedStore) Get(key string) (*News, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	found := s.find(key, CanonicalURL(key))
+	if len(found) == 0 {
+		return nil, ErrNewsNotFound
+	}
+	return cloneNews(s.news[found[0]]), nil
+}
+
+func (s *embeddedStore) Query(query StoreQuery) ([]*News, error) {
+	s.mu.RLock()
+	words := strings.Fields(strings.ToLower(query.Text))
+	var newsList []*News
+	for _, news := range s.news {
+		if news != nil && query.match(news, words) {
+			newsList = append(newsList, cloneNews(news))
+		}
+	}
+	s.mu.RUnlock()
+
+	SortNews(newsList, SortByPublishedDesc)
+	if query.Offset > 0 {
+		if query.Offset >= len(newsList) {
+			return nil, nil
+		}
+		newsList = newsList[query.Offset:]
+	}
+	if query.Limit > 0 && len(newsList) > query.Limit {
+		newsList = newsList[:query.Limit]
+	}
+	return newsList, nil
+}
+
+// Len returns the number of stored news
+func (s *embeddedStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.count
+}
+
+// Compact rewrites the file of the store with one line per news.
+// The store keeps writing to its current file if compacting fails.
+func (s *embeddedStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	tmp := s.path + ".tmp"
+	// the handle stays open through the rename, so that the store never lacks a file
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("error compacting store: %w", err)
+	}
+	if err := s.writeCompacted(file); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("error compacting store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("error compacting store: %w", err)
+	}
+	s.file.Close()
+	s.file = file
+	return nil
+}
+
+// writeCompacted writes every stored news to the file as json lines
+func (s *embeddedStore) writeCompacted(file *os.File) error {
+	w := bufio.NewWriter(file)
+	encoder := json.NewEncoder(w)
+	for _, news := range s.news {
+		if news == nil {
+			continue
+		}
+		if err := encoder.Encode(news); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// Close closes the file of the store, if any
+func (s *embeddedStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
@@ -0,0 +1,318 @@
+package newsapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SQLiteSchema is the sqlite schema of the news table used by NewSQLiteStore.
+// Lists are stored as json arrays and dates as utc rfc 3339 texts of fixed width, which sort chronologically.
+const SQLiteSchema = `CREATE TABLE IF NOT EXISTS news (
+	id INTEGER PRIMARY KEY,
+	guid TEXT UNIQUE,
+	link_key TEXT UNIQUE,
+	title TEXT NOT NULL DEFAULT '',
+	description TEXT NOT NULL DEFAULT '',
+	link TEXT NOT NULL DEFAULT '',
+	links TEXT NOT NULL DEFAULT '[]',
+	content TEXT NOT NULL DEFAULT '',
+	published TEXT NOT NULL DEFAULT '',
+	published_at TEXT,
+	updated TEXT NOT NULL DEFAULT '',
+	updated_at TEXT,
+	image_url TEXT NOT NULL DEFAULT '',
+	categories TEXT NOT NULL DEFAULT '[]',
+	source_link TEXT NOT NULL DEFAULT '',
+	source_title TEXT NOT NULL DEFAULT '',
+	source_image_url TEXT NOT NULL DEFAULT '',
+	source_image_width INTEGER NOT NULL DEFAULT 0,
+	source_image_height INTEGER NOT NULL DEFAULT 0,
+	source_description TEXT NOT NULL DEFAULT '',
+	source_keywords TEXT NOT NULL DEFAULT '[]',
+	source_site_name TEXT NOT NULL DEFAULT '',
+	source_icon_url TEXT NOT NULL DEFAULT '',
+	source_content TEXT NOT NULL DEFAULT '',
+	topic TEXT NOT NULL DEFAULT '',
+	provider TEXT NOT NULL DEFAULT '',
+	editions TEXT NOT NULL DEFAULT '[]',
+	queries TEXT NOT NULL DEFAULT '[]',
+	publisher TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS news_published_at ON news (published_at);
+CREATE INDEX IF NOT EXISTS news_publisher ON news (publisher COLLATE NOCASE);
+CREATE INDEX IF NOT EXISTS news_topic ON news (topic COLLATE NOCASE);
+`
+
+const (
+	sqliteTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+	sqliteColumns = `id, title, description, link, links, content, published, published_at, updated, updated_at,
+	guid, image_url, categories, source_link, source_title, source_image_url, source_image_width, source_image_height,
+	source_description, source_keywords, source_site_name, source_icon_url, source_content, topic, provider, editions, queries`
+
+	sqliteText = `lower(title || ' ' || description || ' ' || source_title || ' ' || source_description || ' ' || source_content)`
+)
+
+// sqliteStore stores the news in a sqlite database
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore returns a store in the sqlite database, creating the news table of SQLiteSchema if needed.
+// Any sqlite driver registered with database/sql can be used, of sqlite 3.38 or later or with the json1 extension
+// for the json_each function, and Close does not close db.
+// Text queries only ignore the case of ascii letters.
+func NewSQLiteStore(db *sql.DB) (*sqliteStore, error) {
+	if _, err := db.Exec(SQLiteSchema); err != nil {
+		return nil, fmt.Errorf("error creating schema: %w", err)
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+// sqliteWriteColumns are the columns written by Upsert
+var sqliteWriteColumns = []string{
+	"guid", "link_key", "title", "description", "link", "links", "content", "published", "published_at", "updated", "updated_at",
+	"image_url", "categories", "source_link", "source_title", "source_image_url", "source_image_width", "source_image_height",
+	"source_description", "source_keywords", "source_site_name", "source_icon_url", "source_content", "topic", "provider",
+	"editions", "queries", "publisher",
+}
+
+func (s *sqliteStore) Upsert(newsList ...*News) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, news := range newsList {
+		if err := upsertSQLiteNews(tx, news); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+	return nil
+}
+
+func upsertSQLiteNews(tx *sql.Tx, news *News) error {
+	guid, linkKey, err := storeKeys(news)
+	if err != nil {
+		return err
+	}
+	rows, err := tx.Query(
+		`SELECT `+sqliteColumns+` FROM news WHERE guid = ? OR link_key = ? ORDER BY guid IS ? DESC`,
+		sqliteNull(guid), sqliteNull(linkKey), sqliteNull(guid),
+	)
+	if err != nil {
+		return fmt.Errorf("error reading news: %w", err)
+	}
+	var ids []int64
+	var found []*News
+	for rows.Next() {
+		id, stored, err := scanSQLiteNews(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+		found = append(found, stored)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading news: %w", err)
+	}
+
+	stored := cloneNews(news)
+	if len(found) > 0 {
+		previous := found[len(found)-1]
+		for k := len(found) - 2; k >= 0; k-- {
+			previous = mergeStoredNews(previous, found[k])
+		}
+		stored = mergeStoredNews(previous, news)
+	}
+	guid, linkKey, _ = storeKeys(stored)
+	values := []any{
+		sqliteNull(guid), sqliteNull(linkKey), stored.Title, stored.Description, stored.Link, sqliteJSON(stored.Links),
+		stored.Content, stored.Published, sqliteTime(stored.PublishedParsed), stored.Updated, sqliteTime(stored.UpdatedParsed),
+		stored.ImageURL, sqliteJSON(stored.Categories), stored.SourceLink, stored.SourceTitle, stored.SourceImageURL,
+		stored.SourceImageWidth, stored.SourceImageHeight, stored.SourceDescription, sqliteJSON(stored.SourceKeywords),
+		stored.SourceSiteName, stored.SourceIconUrl, stored.SourceContent, stored.Topic, stored.Provider,
+		sqliteJSON(stored.Editions), sqliteJSON(stored.Queries), stored.Publisher(),
+	}
+
+	if len(ids) == 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+		_, err = tx.Exec(`INSERT INTO news (`+strings.Join(sqliteWriteColumns, ", ")+`) VALUES (`+placeholders+`)`, values...)
+		if err != nil {
+			return fmt.Errorf("error writing news: %w", err)
+		}
+		return nil
+	}
+	// the news joins two stored news, the other one is merged into it
+	for _, id := range ids[1:] {
+		if _, err := tx.Exec(`DELETE FROM news WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("error writing news: %w", err)
+		}
+	}
+	_, err = tx.Exec(`UPDATE news SET `+strings.Join(sqliteWriteColumns, " = ?, ")+` = ? WHERE id = ?`, append(values, ids[0])...)
+	if err != nil {
+		return fmt.Errorf("error writing news: %w", err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) Get(key string) (*News, error) {
+	_, news, err := scanSQLiteNews(s.db.QueryRow(
+		`SELECT `+sqliteColumns+` FROM news WHERE guid = ? OR link_key = ? ORDER BY guid IS ? DESC LIMIT 1`,
+		key, CanonicalURL(key), key,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNewsNotFound
+	}
+	return news, err
+}
+
+func (s *sqliteStore) Query(query StoreQuery) ([]*News, error) {
+	var where []string
+	var args []any
+	if !query.From.IsZero() {
+		where = append(where, "published_at >= ?")
+		args = append(args, sqliteTime(&query.From))
+	}
+	if !query.To.IsZero() {
+		where = append(where, "published_at < ?")
+		args = append(args, sqliteTime(&query.To))
+	}
+	if query.Publisher != "" {
+		where = append(where, "publisher = ? COLLATE NOCASE")
+		args = append(args, query.Publisher)
+	}
+	if query.Topic != "" {
+		where = append(where, "(topic = ? COLLATE NOCASE OR EXISTS (SELECT 1 FROM json_each(news.categories) WHERE json_each.value = ? COLLATE NOCASE))")
+		args = append(args, query.Topic, query.Topic)
+	}
+	for _, word := range strings.Fields(strings.ToLower(query.Text)) {
+		where = append(where, "instr("+sqliteText+", ?) > 0")
+		args = append(args, word)
+	}
+
+	statement := `SELECT ` + sqliteColumns + ` FROM news`
+	if len(where) > 0 {
+		statement += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	statement += ` ORDER BY published_at IS NULL, published_at DESC, id LIMIT ? OFFSET ?`
+	limit := -1
+	if query.Limit > 0 {
+		limit = query.Limit
+	}
+	args = append(args, limit, query.Offset)
+
+	rows, err := s.db.Query(statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying news: %w", err)
+	}
+	defer rows.Close()
+	var newsList []*News
+	for rows.Next() {
+		_, news, err := scanSQLiteNews(rows)
+		if err != nil {
+			return nil, err
+		}
+		newsList = append(newsList, news)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying news: %w", err)
+	}
+	return newsList, nil
+}
+
+// Close does nothing, the database is closed by its owner
+func (s *sqliteStore) Close() error {
+	return nil
+}
+
+// scanSQLiteNews scans a row of sqliteColumns
+func scanSQLiteNews(row interface{ Scan(dest ...any) error }) (int64, *News, error) {
+	var (
+		id                                                   int64
+		guid                                                 sql.NullString
+		publishedAt, updatedAt                               sql.NullString
+		links, categories, sourceKeywords, editions, queries string
+	)
+	news := &News{}
+	err := row.Scan(
+		&id, &news.Title, &news.Description, &news.Link, &links, &news.Content,
+		&news.Published, &publishedAt, &news.Updated, &updatedAt,
+		&guid, &news.ImageURL, &categories, &news.SourceLink, &news.SourceTitle, &news.SourceImageURL,
+		&news.SourceImageWidth, &news.SourceImageHeight, &news.SourceDescription, &sourceKeywords,
+		&news.SourceSiteName, &news.SourceIconUrl, &news.SourceContent, &news.Topic, &news.Provider,
+		&editions, &queries,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil, err
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("error reading news: %w", err)
+	}
+	news.GUID = guid.String
+	if news.PublishedParsed, err = parseSQLiteTime(publishedAt); err != nil {
+		return 0, nil, err
+	}
+	if news.UpdatedParsed, err = parseSQLiteTime(updatedAt); err != nil {
+		return 0, nil, err
+	}
+	for _, list := range []struct {
+		value string
+		dest  any
+	}{
+		{links, &news.Links},
+		{categories, &news.Categories},
+		{sourceKeywords, &news.SourceKeywords},
+		{editions, &news.Editions},
+		{queries, &news.Queries},
+	} {
+		if err := json.Unmarshal([]byte(list.value), list.dest); err != nil {
+			return 0, nil, fmt.Errorf("error reading news: %w", err)
+		}
+	}
+	return id, news, nil
+}
+
+// sqliteNull returns nil for an empty string, so that unique columns allow several empty values
+func sqliteNull(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func sqliteJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return "[]"
+	}
+	return string(data)
+}
+
+func sqliteTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+func parseSQLiteTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid || s.String == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(sqliteTimeLayout, s.String)
+	if err != nil {
+		return nil, fmt.Errorf("error reading news date: %w", err)
+	}
+	return &t, nil
+}
@@ -0,0 +1,34 @@
+package newsapi
+
+import (
+	"database/sql"
+	"testing"
+)
+
+// TestSQLiteStore runs with the sqlite driver registered by the test binary, if any.
+// No driver is a dependency of the module, see the README to register one.
+func TestSQLiteStore(t *testing.T) {
+	var driver string
+	for _, name := range sql.Drivers() {
+		if name == "sqlite" || name == "sqlite3" {
+			driver = name
+		}
+	}
+	if driver == "" {
+		t.Skip("no sqlite driver registered")
+	}
+	for _, test := range []func(t *testing.T, store Store){testStore, testStoreJoinsNews} {
+		db, err := sql.Open(driver, ":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// every connection to :memory: is another database
+		db.SetMaxOpenConns(1)
+		store, err := NewSQLiteStore(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		test(t, store)
+		db.Close()
+	}
+}
@@ -0,0 +1,211 @@
+package newsapi
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func storeTestNews() []*News {
+	at := func(hour int) *time.Time {
+		t := time.Date(2024, 5, 1, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+	return []*News{
+		{GUID: "g1", Title: "Chip export rules tightened - Reuters", Link: "https://news.google.com/rss/articles/g1", Provider: ProviderGoogle, PublishedParsed: at(8), Topic: TopicBusiness},
+		{GUID: "g2", Title: "Rain expected", Link: "https://weather.example.com/rain", PublishedParsed: at(9)},
+		{Title: "Local election results", Link: "https://local.example.com/election?utm_source=feed", PublishedParsed: at(10), Categories: []string{"Politics"}},
+	}
+}
+
+// testStore checks the behaviour shared by every Store
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+	if err := store.Upsert(storeTestNews()...); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if err := store.Upsert(&News{Title: "no key"}); !errors.Is(err, ErrEmptyNewsKey) {
+		t.Errorf("Upsert without key: got %v, want ErrEmptyNewsKey", err)
+	}
+
+	// an upsert without the enriched fields keeps them
+	if err := store.Upsert(&News{GUID: "g2", Title: "Rain expected", SourceLink: "https://weather.example.com/rain", SourceSiteName: "Weather"}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if err := store.Upsert(&News{GUID: "g2", Title: "Rain expected tonight", Link: "https://weather.example.com/rain"}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	news, err := store.Get("g2")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if news.Title != "Rain expected tonight" || news.SourceSiteName != "Weather" || news.PublishedParsed == nil {
+		t.Errorf("Get merged news: got %+v", news)
+	}
+	if news, err := store.Get("https://local.example.com/election"); err != nil || news.Title != "Local election results" {
+		t.Errorf("Get by canonical link: got %v, %v", news, err)
+	}
+	if _, err := store.Get("missing"); !errors.Is(err, ErrNewsNotFound) {
+		t.Errorf("Get missing: got %v, want ErrNewsNotFound", err)
+	}
+
+	tests := []struct {
+		name  string
+		query StoreQuery
+		want  []string
+	}{
+		{"all", StoreQuery{}, []string{"Local election results", "Rain expected tonight", "Chip export rules tightened - Reuters"}},
+		{"range", StoreQuery{From: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC), To: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}, []string{"Rain expected tonight"}},
+		{"publisher", StoreQuery{Publisher: "reuters"}, []string{"Chip export rules tightened - Reuters"}},
+		{"topic", StoreQuery{Topic: TopicBusiness}, []string{"Chip export rules tightened - Reuters"}},
+		{"category", StoreQuery{Topic: "politics"}, []string{"Local election results"}},
+		{"text", StoreQuery{Text: "EXPORT chip"}, []string{"Chip export rules tightened - Reuters"}},
+		{"page", StoreQuery{Limit: 1, Offset: 1}, []string{"Rain expected tonight"}},
+	}
+	for _, tt := range tests {
+		newsList, err := store.Query(tt.query)
+		if err != nil {
+			t.Fatalf("Query %s: %v", tt.name, err)
+		}
+		var titles []string
+		for _, news := range newsList {
+			titles = append(titles, news.Title)
+		}
+		if len(titles) != len(tt.want) {
+			t.Errorf("Query %s: got %q, want %q", tt.name, titles, tt.want)
+			continue
+		}
+		for i := range titles {
+			if titles[i] != tt.want[i] {
+				t.Errorf("Query %s: got %q, want %q", tt.name, titles, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	testStore(t, store)
+	if store.Len() != 3 {
+		t.Errorf("Len: got %d, want 3", store.Len())
+	}
+}
+
+// testStoreJoinsNews checks that a news with the keys of two stored news replaces both, in an empty store
+func testStoreJoinsNews(t *testing.T, store Store) {
+	t.Helper()
+	if err := store.Upsert(&News{GUID: "a", Title: "A"}, &News{Title: "B", SourceLink: "https://example.com/b", SourceSiteName: "Example"}); err != nil {
+		t.Fatal(err)
+	}
+	// the news has the guid of one stored news and the link of the other one
+	if err := store.Upsert(&News{GUID: "a", Title: "A and B", SourceLink: "https://example.com/b"}); err != nil {
+		t.Fatal(err)
+	}
+	if newsList, err := store.Query(StoreQuery{}); err != nil || len(newsList) != 1 {
+		t.Fatalf("Query: got %d news, %v, want 1", len(newsList), err)
+	}
+	news, err := store.Get("https://example.com/b")
+	if err != nil || news.Title != "A and B" || news.SourceSiteName != "Example" {
+		t.Errorf("Get: got %+v, %v", news, err)
+	}
+}
+
+func TestMemoryStoreJoinsNews(t *testing.T) {
+	testStoreJoinsNews(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "news.jsonl")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = NewFileStore(path)
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	defer store.Close()
+	if store.Len() != 3 {
+		t.Fatalf("Len after reopening: got %d, want 3", store.Len())
+	}
+	if news, err := store.Get("g2"); err != nil || news.SourceSiteName != "Weather" {
+		t.Errorf("Get after reopening: got %+v, %v", news, err)
+	}
+	if err := store.Compact(); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if err := store.Upsert(&News{GUID: "g4", Title: "After compaction"}); err != nil {
+		t.Fatalf("Upsert after compaction: %v", err)
+	}
+	store.Close()
+
+	store, err = NewFileStore(path)
+	if err != nil {
+		t.Fatalf("reopening after compaction: %v", err)
+	}
+	defer store.Close()
+	if store.Len() != 4 {
+		t.Errorf("Len after compaction: got %d, want 4", store.Len())
+	}
+}
+
+func TestFileStoreCompactError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "news.jsonl")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Upsert(&News{GUID: "a", Title: "A"}); err != nil {
+		t.Fatal(err)
+	}
+	// the temporary file cannot be created over a directory
+	if err := os.Mkdir(path+".tmp", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Compact(); err == nil {
+		t.Fatal("Compact: got no error")
+	}
+	// the store still writes to its file
+	if err := store.Upsert(&News{GUID: "b", Title: "B"}); err != nil {
+		t.Fatalf("Upsert after a failed compaction: %v", err)
+	}
+	store.Close()
+
+	store, err = NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if store.Len() != 2 {
+		t.Errorf("Len after reopening: got %d, want 2", store.Len())
+	}
+}
+
+func TestFileStoreWriteError(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "news.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Upsert(&News{GUID: "a", Title: "A"}); err != nil {
+		t.Fatal(err)
+	}
+	store.file.Close()
+	if err := store.Upsert(&News{GUID: "a", Title: "A updated"}, &News{GUID: "b", Title: "B"}); err == nil {
+		t.Fatal("Upsert to a closed file: got no error")
+	}
+	// the news which could not be written are not stored in memory either
+	if news, err := store.Get("a"); err != nil || news.Title != "A" {
+		t.Errorf("Get: got %+v, %v, want the news before the failed upsert", news, err)
+	}
+	if _, err := store.Get("b"); !errors.Is(err, ErrNewsNotFound) {
+		t.Errorf("Get: got %v, want ErrNewsNotFound", err)
+	}
+}
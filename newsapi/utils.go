@@ -15,6 +15,7 @@ import (
 )
 
 var (
+	// Deprecated: use DefaultHeaderProfiles instead, these user agents are outdated
 	USER_AGENTS = []string{
 		"Mozilla/5.0 (Windows NT 6.1) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/41.0.2228.0 Safari/537.36",
 		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_10_1) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/41.0.2227.1 Safari/537.36",
@@ -202,8 +203,9 @@ var (
 		"Mozilla/5.0 (Windows  U  Windows NT 5.1  en-US) AppleWebKit/534.12 (KHTML, like Gecko) Chrome/9.0.583.0 Safari/534.12",
 	}
 
+	// RandomUserAgent returns the user agent of a random profile of DefaultHeaderProfiles
 	RandomUserAgent = func() string {
-		return USER_AGENTS[rand.Intn(len(USER_AGENTS))]
+		return DefaultHeaderProfiles[rand.Intn(len(DefaultHeaderProfiles))].UserAgent
 	}
 )
 
@@ -242,6 +244,9 @@ func GetFeedItems(client *http.Client, req *http.Request) ([]*gofeed.Item, error
 		return nil, fmt.Errorf("error getting response: %w", err)
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+	}
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("error reading response body: %w", err)
@@ -260,9 +265,13 @@ func IsNewsApiLink(link string) bool {
 }
 
 // GetOriginalLink gets the original link
-func GetOriginalLink(sourceLink string) (string, error) {
+func GetOriginalLink(sourceLink string, options ...FetchOption) (string, error) {
+	return getOriginalLink(sourceLink, newFetchConfig(options))
+}
+
+func getOriginalLink(sourceLink string, config *fetchConfig) (string, error) {
 	originalLink := ""
-	c := colly.NewCollector(colly.Async(true))
+	c := config.newCollector()
 	c.OnHTML("a[href]", func(e *colly.HTMLElement) {
 		originalLink = e.Attr("href")
 	})
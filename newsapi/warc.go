@@ -0,0 +1,289 @@
+package newsapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	warcVersion        = "WARC/1.1"
+	defaultWARCPrefix  = "newsapi"
+	defaultWARCMaxSize = 1 << 30
+)
+
+// WARCWriter writes http exchanges to WARC 1.1 files in a directory, starting a new file when the current one is too large or too old
+type WARCWriter struct {
+	mu       sync.Mutex
+	dir      string
+	prefix   string
+	maxSize  int64
+	maxAge   time.Duration
+	compress bool
+	// errorHandler receives the archiving errors of the transports instead of failing their requests
+	errorHandler func(err error)
+
+	file    *os.File
+	size    int64
+	opened  time.Time
+	serial  int
+	written []string
+}
+
+type WARCOption func(*WARCWriter)
+
+// WithWARCPrefix sets the prefix of the file names, "newsapi" by default
+func WithWARCPrefix(prefix string) WARCOption {
+	return func(w *WARCWriter) {
+		w.prefix = prefix
+	}
+}
+
+// WithWARCMaxSize sets the size in bytes after which a new file is started, 1 GiB by default
+func WithWARCMaxSize(size int64) WARCOption {
+	return func(w *WARCWriter) {
+		if size > 0 {
+			w.maxSize = size
+		}
+	}
+}
+
+// WithWARCMaxAge sets the age after which a new file is started, files are not rotated by age by default
+func WithWARCMaxAge(age time.Duration) WARCOption {
+	return func(w *WARCWriter) {
+		w.maxAge = age
+	}
+}
+
+// WithWARCCompression compresses every record as a gzip member, writing .warc.gz files
+func WithWARCCompression() WARCOption {
+	return func(w *WARCWriter) {
+		w.compress = true
+	}
+}
+
+// WithWARCErrorHandler reports the archiving errors of the transports to handler, instead of failing the requests
+func WithWARCErrorHandler(handler func(err error)) WARCOption {
+	return func(w *WARCWriter) {
+		w.errorHandler = handler
+	}
+}
+
+// NewWARCWriter returns a writer creating its files in dir
+func NewWARCWriter(dir string, options ...WARCOption) (*WARCWriter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating warc directory: %w", err)
+	}
+	w := &WARCWriter{
+		dir:     dir,
+		prefix:  defaultWARCPrefix,
+		maxSize: defaultWARCMaxSize,
+	}
+	for _, option := range options {
+		option(w)
+	}
+	return w, nil
+}
+
+// Files returns the paths of the files written so far
+func (w *WARCWriter) Files() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.written...)
+}
+
+// WriteExchange writes a request record and its response record.
+// The response is stored as received by the client, after the transport decoded its transfer and content encodings.
+func (w *WARCWriter) WriteExchange(req *http.Request, reqBody []byte, resp *http.Response, respBody []byte) error {
+	now := time.Now().UTC()
+	target := req.URL.String()
+
+	var request bytes.Buffer
+	fmt.Fprintf(&request, "%s %s HTTP/1.1\r\nHost: %s\r\n", req.Method, req.URL.RequestURI(), req.URL.Host)
+	req.Header.Write(&request)
+	request.WriteString("\r\n")
+	request.Write(reqBody)
+
+	var response bytes.Buffer
+	fmt.Fprintf(&response, "HTTP/1.1 %s\r\n", resp.Status)
+	header := resp.Header.Clone()
+	header.Del("Transfer-Encoding")
+	header.Set("Content-Length", strconv.Itoa(len(respBody)))
+	header.Write(&response)
+	response.WriteString("\r\n")
+	response.Write(respBody)
+
+	requestID := warcRecordID()
+	responseID := warcRecordID()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.rotate(now); err != nil {
+		return err
+	}
+	if err := w.writeRecord([][2]string{
+		{"WARC-Type", "response"},
+		{"WARC-Record-ID", responseID},
+		{"WARC-Date", now.Format(time.RFC3339Nano)},
+		{"WARC-Target-URI", target},
+		{"WARC-Concurrent-To", requestID},
+		{"WARC-Payload-Digest", warcDigest(respBody)},
+		{"WARC-Block-Digest", warcDigest(response.Bytes())},
+		{"Content-Type", "application/http;msgtype=response"},
+	}, response.Bytes()); err != nil {
+		return err
+	}
+	return w.writeRecord([][2]string{
+		{"WARC-Type", "request"},
+		{"WARC-Record-ID", requestID},
+		{"WARC-Date", now.Format(time.RFC3339Nano)},
+		{"WARC-Target-URI", target},
+		{"WARC-Concurrent-To", responseID},
+		{"WARC-Block-Digest", warcDigest(request.Bytes())},
+		{"Content-Type", "application/http;msgtype=request"},
+	}, request.Bytes())
+}
+
+// rotate opens a new file if there is none or if the current one is too large or too old
+func (w *WARCWriter) rotate(now time.Time) error {
+	if w.file != nil && w.size < w.maxSize && (w.maxAge <= 0 || now.Sub(w.opened) < w.maxAge) {
+		return nil
+	}
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return fmt.Errorf("error closing warc file: %w", err)
+		}
+		w.file = nil
+	}
+
+	w.serial++
+	name := fmt.Sprintf("%s-%s-%05d.warc", w.prefix, now.Format("20060102150405"), w.serial)
+	if w.compress {
+		name += ".gz"
+	}
+	path := filepath.Join(w.dir, name)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("error creating warc file: %w", err)
+	}
+	w.file = file
+	w.size = 0
+	w.opened = now
+	w.written = append(w.written, path)
+
+	info := []byte("software: newsApi-go\r\nformat: WARC File Format 1.1\r\nconformsTo: http://iipc.github.io/warc-specifications/specifications/warc-format/warc-1.1/\r\n")
+	return w.writeRecord([][2]string{
+		{"WARC-Type", "warcinfo"},
+		{"WARC-Record-ID", warcRecordID()},
+		{"WARC-Date", now.Format(time.RFC3339Nano)},
+		{"WARC-Filename", name},
+		{"Content-Type", "application/warc-fields"},
+	}, info)
+}
+
+func (w *WARCWriter) writeRecord(fields [][2]string, block []byte) error {
+	var record bytes.Buffer
+	record.WriteString(warcVersion + "\r\n")
+	for _, field := range fields {
+		fmt.Fprintf(&record, "%s: %s\r\n", field[0], field[1])
+	}
+	fmt.Fprintf(&record, "Content-Length: %d\r\n\r\n", len(block))
+	record.Write(block)
+	record.WriteString("\r\n\r\n")
+
+	data := record.Bytes()
+	if w.compress {
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		gz.Write(data)
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("error compressing warc record: %w", err)
+		}
+		data = compressed.Bytes()
+	}
+	n, err := w.file.Write(data)
+	w.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("error writing warc record: %w", err)
+	}
+	return nil
+}
+
+// Close closes the current file
+func (w *WARCWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// warcRecordID returns a new random record id
+func warcRecordID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func warcDigest(data []byte) string {
+	sum := sha1.Sum(data)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
+
+// warcTransport archives the exchanges of its transport
+type warcTransport struct {
+	transport http.RoundTripper
+	writer    *WARCWriter
+}
+
+// NewWARCTransport returns a transport archiving every exchange of transport to w, http.DefaultTransport if nil.
+// A request fails when its exchange cannot be archived, unless w has an error handler, see WithWARCErrorHandler.
+func NewWARCTransport(transport http.RoundTripper, w *WARCWriter) http.RoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &warcTransport{transport: transport, writer: w}
+}
+
+func (t *warcTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if err := t.writer.WriteExchange(req, reqBody, resp, respBody); err != nil {
+		err = fmt.Errorf("error archiving %s: %w", req.URL, err)
+		if t.writer.errorHandler == nil {
+			return nil, err
+		}
+		t.writer.errorHandler(err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,150 @@
+package newsapi
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WARCRecord is a record of a WARC file
+type WARCRecord struct {
+	// Type is the WARC-Type, e.g. "response"
+	Type      string
+	TargetURI string
+	Date      time.Time
+	Header    textproto.MIMEHeader
+	// Content is the record block, e.g. the http response
+	Content []byte
+}
+
+// Response parses the http response of a response record
+func (r *WARCRecord) Response(req *http.Request) (*http.Response, error) {
+	if r.Type != "response" {
+		return nil, fmt.Errorf("%w: %s record", ErrInvalidWARC, r.Type)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(r.Content)), req)
+	if err != nil {
+		return nil, fmt.Errorf("error reading archived response: %w", err)
+	}
+	return resp, nil
+}
+
+// WARCReader reads the records of a WARC file, compressed or not
+type WARCReader struct {
+	reader *bufio.Reader
+	text   *textproto.Reader
+}
+
+// NewWARCReader returns a reader of the WARC file r
+func NewWARCReader(r io.Reader) (*WARCReader, error) {
+	reader := bufio.NewReader(r)
+	if magic, err := reader.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("error reading warc: %w", err)
+		}
+		reader = bufio.NewReader(gz)
+	}
+	return &WARCReader{reader: reader, text: textproto.NewReader(reader)}, nil
+}
+
+// Next returns the next record, or io.EOF after the last one
+func (r *WARCReader) Next() (*WARCRecord, error) {
+	version, err := r.text.ReadLine()
+	for err == nil && version == "" {
+		version, err = r.text.ReadLine()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(version, "WARC/") {
+		return nil, fmt.Errorf("%w: unexpected line %q", ErrInvalidWARC, version)
+	}
+	header, err := r.text.ReadMIMEHeader()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWARC, err)
+	}
+	length, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("%w: invalid content length", ErrInvalidWARC)
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r.reader, content); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWARC, err)
+	}
+	record := &WARCRecord{
+		Type:      header.Get("WARC-Type"),
+		TargetURI: strings.Trim(header.Get("WARC-Target-URI"), "<>"),
+		Header:    header,
+		Content:   content,
+	}
+	record.Date, _ = time.Parse(time.RFC3339Nano, header.Get("WARC-Date"))
+	return record, nil
+}
+
+// WARCReplay is a transport answering requests with the responses archived in WARC files, without the network
+type WARCReplay struct {
+	mu        sync.RWMutex
+	responses map[string]*WARCRecord
+}
+
+// NewWARCReplay returns a transport replaying the responses of the WARC files, the latest response of a url wins.
+// It can be used with WithFetchTransport to extract the source contents again.
+func NewWARCReplay(paths ...string) (*WARCReplay, error) {
+	replay := &WARCReplay{responses: map[string]*WARCRecord{}}
+	for _, path := range paths {
+		if err := replay.load(path); err != nil {
+			return nil, err
+		}
+	}
+	return replay, nil
+}
+
+func (t *WARCReplay) load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening warc: %w", err)
+	}
+	defer file.Close()
+	reader, err := NewWARCReader(file)
+	if err != nil {
+		return err
+	}
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", path, err)
+		}
+		if record.Type != "response" {
+			continue
+		}
+		t.mu.Lock()
+		if stored, ok := t.responses[record.TargetURI]; !ok || !record.Date.Before(stored.Date) {
+			t.responses[record.TargetURI] = record
+		}
+		t.mu.Unlock()
+	}
+}
+
+func (t *WARCReplay) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.RLock()
+	record, ok := t.responses[req.URL.String()]
+	t.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotArchived, req.URL)
+	}
+	return record.Response(req)
+}
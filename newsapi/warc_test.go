@@ -0,0 +1,93 @@
+package newsapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWARCRoundTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, "<html>"+r.URL.Path+"</html>")
+	}))
+	defer server.Close()
+
+	for _, options := range [][]WARCOption{nil, {WithWARCCompression()}} {
+		writer, err := NewWARCWriter(t.TempDir(), options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := &http.Client{Transport: NewWARCTransport(nil, writer)}
+		for _, path := range []string{"/a", "/b"} {
+			resp, err := client.Get(server.URL + path)
+			if err != nil {
+				t.Fatalf("Get %s: %v", path, err)
+			}
+			resp.Body.Close()
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		replay, err := NewWARCReplay(writer.Files()...)
+		if err != nil {
+			t.Fatalf("NewWARCReplay: %v", err)
+		}
+		replayClient := &http.Client{Transport: replay}
+		resp, err := replayClient.Get(server.URL + "/b")
+		if err != nil {
+			t.Fatalf("replaying: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "<html>/b</html>" {
+			t.Errorf("replayed body: got %q", body)
+		}
+		if _, err := replayClient.Get(server.URL + "/c"); !errors.Is(err, ErrNotArchived) {
+			t.Errorf("replaying an unarchived url: got %v, want ErrNotArchived", err)
+		}
+	}
+}
+
+func TestWARCTransportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "archive")
+	writer, err := NewWARCWriter(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the files cannot be created anymore
+	os.RemoveAll(dir)
+
+	client := &http.Client{Transport: NewWARCTransport(nil, writer)}
+	if _, err := client.Get(server.URL); err == nil {
+		t.Error("Get without archive: got no error")
+	}
+
+	var reported []error
+	writer, err = NewWARCWriter(dir, WithWARCErrorHandler(func(err error) {
+		reported = append(reported, err)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.RemoveAll(dir)
+	client = &http.Client{Transport: NewWARCTransport(nil, writer)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get with an error handler: %v", err)
+	}
+	resp.Body.Close()
+	if len(reported) != 1 {
+		t.Errorf("reported errors: got %v, want 1", reported)
+	}
+}
@@ -0,0 +1,253 @@
+package newsapi
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultWatchInterval = time.Minute
+	defaultWatchJitter   = 0.1
+	defaultMaxBackoff    = 30 * time.Minute
+)
+
+// Watcher polls a query periodically and delivers only the news it has not seen yet
+type Watcher struct {
+	api   NewsApi
+	query Query
+	store SeenStore
+
+	interval   time.Duration
+	jitter     float64
+	maxBackoff time.Duration
+	skipFirst  bool
+
+	handler      func(news *News)
+	errorHandler func(err error)
+	newsCh       chan *News
+	started      atomic.Bool
+}
+
+type WatcherOption func(*Watcher)
+
+// WithInterval sets the interval between two polls
+func WithInterval(interval time.Duration) WatcherOption {
+	return func(w *Watcher) {
+		if interval > 0 {
+			w.interval = interval
+		}
+	}
+}
+
+// WithJitter sets the random variation of the interval as a fraction of it, e.g. 0.1 for ±10%
+func WithJitter(jitter float64) WatcherOption {
+	return func(w *Watcher) {
+		if jitter >= 0 && jitter < 1 {
+			w.jitter = jitter
+		}
+	}
+}
+
+// WithMaxBackoff sets the maximal interval after consecutive errors, the interval doubles on every error
+func WithMaxBackoff(maxBackoff time.Duration) WatcherOption {
+	return func(w *Watcher) {
+		if maxBackoff > 0 {
+			w.maxBackoff = maxBackoff
+		}
+	}
+}
+
+// WithSeenStore sets the store of the seen news, an unlimited memory store by default
+func WithSeenStore(store SeenStore) WatcherOption {
+	return func(w *Watcher) {
+		w.store = store
+	}
+}
+
+// WithNewsHandler delivers the new news to handler instead of the News channel
+func WithNewsHandler(handler func(news *News)) WatcherOption {
+	return func(w *Watcher) {
+		w.handler = handler
+	}
+}
+
+// WithErrorHandler sets the handler of the poll errors, which are ignored by default
+func WithErrorHandler(handler func(err error)) WatcherOption {
+	return func(w *Watcher) {
+		w.errorHandler = handler
+	}
+}
+
+// WithoutInitialNews marks the news of the first poll as seen without delivering them
+func WithoutInitialNews() WatcherOption {
+	return func(w *Watcher) {
+		w.skipFirst = true
+	}
+}
+
+// NewWatcher returns a watcher polling the query with api
+func NewWatcher(api NewsApi, query Query, options ...WatcherOption) *Watcher {
+	w := &Watcher{
+		api:        api,
+		query:      query,
+		interval:   defaultWatchInterval,
+		jitter:     defaultWatchJitter,
+		maxBackoff: defaultMaxBackoff,
+		newsCh:     make(chan *News),
+	}
+	for _, option := range options {
+		option(w)
+	}
+	if w.store == nil {
+		w.store = NewMemorySeenStore(0)
+	}
+	return w
+}
+
+// News returns the channel of the new news, which is closed when Run returns.
+// It is not used when a news handler is set.
+func (w *Watcher) News() <-chan *News {
+	return w.newsCh
+}
+
+// Poll runs the query once and returns the news not seen yet, marking them as seen
+func (w *Watcher) Poll() ([]*News, error) {
+	newsList, err := w.poll()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.store.MarkSeen(seenKeys(newsList)...); err != nil {
+		return nil, err
+	}
+	return newsList, nil
+}
+
+// poll runs the query once and returns the news not seen yet
+func (w *Watcher) poll() ([]*News, error) {
+	if err := w.query.validate(); err != nil {
+		return nil, err
+	}
+	newsList, err := w.query.fetch(w.api)
+	if err != nil {
+		return nil, err
+	}
+	newsList = MergeNews(newsList)
+	fresh := make([]*News, 0, len(newsList))
+	for _, news := range newsList {
+		seen, err := w.store.Seen(seenKey(news))
+		if err != nil {
+			return nil, err
+		}
+		if !seen {
+			fresh = append(fresh, news)
+		}
+	}
+	// deliver the oldest news first
+	SortNews(fresh, SortByPublishedAsc)
+	return fresh, nil
+}
+
+// Run polls until ctx is done, then closes the News channel and returns ctx.Err().
+// A news is marked as seen once it is delivered.
+// A watcher runs only once, later calls return ErrWatcherStarted.
+func (w *Watcher) Run(ctx context.Context) error {
+	if !w.started.CompareAndSwap(false, true) {
+		return ErrWatcherStarted
+	}
+	defer close(w.newsCh)
+	if err := w.query.validate(); err != nil {
+		return err
+	}
+
+	failures := 0
+	first := true
+	for {
+		newsList, err := w.poll()
+		if err != nil {
+			failures++
+			if w.errorHandler != nil {
+				w.errorHandler(err)
+			}
+		} else {
+			failures = 0
+			if first && w.skipFirst {
+				if err := w.store.MarkSeen(seenKeys(newsList)...); err != nil && w.errorHandler != nil {
+					w.errorHandler(err)
+				}
+			} else if err := w.deliver(ctx, newsList); err != nil {
+				return err
+			}
+			first = false
+		}
+
+		timer := time.NewTimer(w.nextDelay(failures))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// deliver sends the news to the handler or the channel, it only fails when ctx is done
+func (w *Watcher) deliver(ctx context.Context, newsList []*News) error {
+	for _, news := range newsList {
+		if w.handler != nil {
+			w.handler(news)
+		} else {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case w.newsCh <- news:
+			}
+		}
+		if err := w.store.MarkSeen(seenKey(news)); err != nil && w.errorHandler != nil {
+			w.errorHandler(err)
+		}
+	}
+	return nil
+}
+
+// nextDelay returns the jittered interval, doubled for every consecutive failure up to the max backoff.
+// The delay after a failure is never shorter than the interval, nor longer than the max backoff.
+func (w *Watcher) nextDelay(failures int) time.Duration {
+	maxDelay := w.maxBackoff
+	if maxDelay < w.interval {
+		maxDelay = w.interval
+	}
+	delay := w.interval
+	for i := 0; i < failures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if w.jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * w.jitter * float64(delay))
+	}
+	if failures > 0 {
+		if delay > maxDelay {
+			delay = maxDelay
+		}
+		if delay < w.interval {
+			delay = w.interval
+		}
+	}
+	return delay
+}
+
+// seenKey returns the key identifying the news in a seen store
+func seenKey(news *News) string {
+	if news.GUID != "" {
+		return news.GUID
+	}
+	return news.dedupKey()
+}
+
+func seenKeys(newsList []*News) []string {
+	keys := make([]string, 0, len(newsList))
+	for _, news := range newsList {
+		keys = append(keys, seenKey(news))
+	}
+	return keys
+}
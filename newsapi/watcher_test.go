@@ -0,0 +1,144 @@
+package newsapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeQuery returns a query answering the polls with the results in turn, the last one again once they run out
+func fakeQuery(results ...func() ([]*News, error)) Query {
+	var mu sync.Mutex
+	polls := 0
+	return Query{Name: "fake", fetch: func(api NewsApi) ([]*News, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		result := results[len(results)-1]
+		if polls < len(results) {
+			result = results[polls]
+		}
+		polls++
+		return result()
+	}}
+}
+
+func watcherTestNews(guids ...string) func() ([]*News, error) {
+	return func() ([]*News, error) {
+		newsList := make([]*News, 0, len(guids))
+		for i, guid := range guids {
+			published := time.Date(2024, 5, 1, i, 0, 0, 0, time.UTC)
+			newsList = append(newsList, &News{GUID: guid, Title: "News " + guid, PublishedParsed: &published})
+		}
+		return newsList, nil
+	}
+}
+
+func TestWatcherNextDelay(t *testing.T) {
+	w := NewWatcher(nil, fakeQuery(), WithInterval(time.Minute), WithMaxBackoff(5*time.Minute), WithJitter(0))
+	for failures, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute} {
+		if got := w.nextDelay(failures); got != want {
+			t.Errorf("%d failures: got %v, want %v", failures, got, want)
+		}
+	}
+	if got := w.nextDelay(100); got != 5*time.Minute {
+		t.Errorf("100 failures: got %v, want the max backoff", got)
+	}
+
+	// a max backoff shorter than the interval does not shorten the delay after a failure, even jittered
+	w = NewWatcher(nil, fakeQuery(), WithInterval(time.Minute), WithMaxBackoff(30*time.Second), WithJitter(0.5))
+	for i := 0; i < 100; i++ {
+		if got := w.nextDelay(1); got != time.Minute {
+			t.Fatalf("got %v, want the interval", got)
+		}
+		if got := w.nextDelay(0); got < 30*time.Second || got > 90*time.Second {
+			t.Fatalf("without failure: got %v, want the interval ±50%%", got)
+		}
+	}
+}
+
+func TestWatcherPoll(t *testing.T) {
+	w := NewWatcher(nil, fakeQuery(watcherTestNews("b", "a"), watcherTestNews("a", "b", "c")))
+	newsList, err := w.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the oldest news first
+	if len(newsList) != 2 || newsList[0].GUID != "b" || newsList[1].GUID != "a" {
+		t.Fatalf("first poll: got %v", newsList)
+	}
+	if newsList, err = w.Poll(); err != nil || len(newsList) != 1 || newsList[0].GUID != "c" {
+		t.Errorf("second poll: got %v, %v, want only c", newsList, err)
+	}
+	if _, err := NewWatcher(nil, Query{Name: "nothing"}).Poll(); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("query without fetch function: got %v, want ErrInvalidQuery", err)
+	}
+}
+
+func TestWatcherRun(t *testing.T) {
+	failure := errors.New("feed unavailable")
+	query := fakeQuery(
+		watcherTestNews("a", "b"),
+		func() ([]*News, error) { return nil, failure },
+		watcherTestNews("a", "b", "c"),
+		watcherTestNews("a", "b", "c", "d"),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var delivered []string
+	var errs []error
+	w := NewWatcher(nil, query,
+		WithInterval(time.Millisecond),
+		WithMaxBackoff(time.Millisecond),
+		WithJitter(0),
+		WithoutInitialNews(),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }),
+		WithNewsHandler(func(news *News) {
+			delivered = append(delivered, news.GUID)
+			if news.GUID == "d" {
+				cancel()
+			}
+		}),
+	)
+	if err := w.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run: got %v, want context.Canceled", err)
+	}
+	// the news of the first poll are skipped, and the failed poll does not stop the watcher
+	if len(delivered) != 2 || delivered[0] != "c" || delivered[1] != "d" {
+		t.Errorf("got delivered %v, want [c d]", delivered)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], failure) {
+		t.Errorf("got errors %v", errs)
+	}
+	if seen, _ := w.store.Seen("a"); !seen {
+		t.Error("the skipped news were not marked as seen")
+	}
+	if err := w.Run(ctx); !errors.Is(err, ErrWatcherStarted) {
+		t.Errorf("second Run: got %v, want ErrWatcherStarted", err)
+	}
+}
+
+func TestWatcherRunChannel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := NewWatcher(nil, fakeQuery(watcherTestNews("a", "b")), WithInterval(time.Millisecond))
+	done := make(chan error)
+	go func() {
+		done <- w.Run(ctx)
+	}()
+	var received []string
+	for news := range w.News() {
+		received = append(received, news.GUID)
+		if len(received) == 2 {
+			// the later polls find no new news
+			cancel()
+		}
+	}
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run: got %v, want context.Canceled", err)
+	}
+	if len(received) != 2 || received[0] != "a" || received[1] != "b" {
+		t.Errorf("got received %v, want [a b] once", received)
+	}
+}